@@ -0,0 +1,134 @@
+package main
+
+import (
+	"RocketContainer.go/graph"
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/importer"
+	"context"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// importActor actor that the audit log records for the changes made by the import subcommand.
+	importActor = "import"
+	importUsage = "usage: import [-format csv|json|ndjson] [-dry-run] [-continue-on-error] file"
+)
+
+// runImport run the import subcommand: import the containers, videos, and assets of a file, like the importCatalog
+// mutation, and print what became of each record. Exits with status 1 if any record failed.
+func runImport(logger *zap.Logger, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), importUsage)
+		flags.PrintDefaults()
+	}
+
+	formatName := flags.String("format", "", "format of the file; defaults to the one of its extension")
+	dryRun := flags.Bool("dry-run", false, "roll back every record, only reporting what importing them would do")
+	continueOnError := flags.Bool("continue-on-error", false, "keep the records that were imported if others fail")
+
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		logger.Fatal(importUsage)
+	}
+
+	name := flags.Arg(0)
+
+	format, formatErr := importer.FormatOf(name)
+	if *formatName != "" {
+		format, formatErr = importer.ParseFormat(*formatName)
+	}
+
+	if formatErr != nil {
+		logger.Fatal("failed to determine the import format", zap.String("file", name), zap.Error(formatErr))
+	}
+
+	file, openErr := os.Open(name)
+	if openErr != nil {
+		logger.Fatal("failed to open import file", zap.Error(openErr))
+	}
+	defer file.Close()
+
+	rows, readErr := importer.Read(file, format)
+	if readErr != nil {
+		logger.Fatal("failed to read import file", zap.String("file", name), zap.Error(readErr))
+	}
+
+	resolver := graph.Resolver{AcceptNumericIDs: acceptNumericIDs(logger), Store: data.InitDb()}
+	ctx := data.WithActor(context.Background(), importActor)
+
+	report, importErr := resolver.Import(
+		ctx,
+		rows,
+		data.ImportOptions{ContinueOnError: *continueOnError, DryRun: *dryRun},
+	)
+	if importErr != nil {
+		logger.Fatal("failed to import catalog", zap.Error(importErr))
+	}
+
+	printImportReport(os.Stdout, report)
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// printImportReport print a row for each line of report, or each of its errors, followed by a summary.
+func printImportReport(output io.Writer, report *model.ImportReport) {
+	writer := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "LINE\tTYPE\tEXTERNAL ID\tRESULT")
+
+	for _, line := range report.Lines {
+		prefix := fmt.Sprintf("%d\t%s\t%s", line.Line, orDash(line.Type), orDash(line.ExternalID))
+
+		if line.Action != nil {
+			result := string(*line.Action)
+			if line.ID != nil {
+				result += " " + *line.ID
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\n", prefix, result)
+		}
+
+		for _, userError := range line.Errors {
+			message := userError.Message
+			if len(userError.Field) > 0 {
+				message = strings.Join(userError.Field, ".") + ": " + message
+			}
+
+			fmt.Fprintf(writer, "%s\t%s %s\n", prefix, userError.Code, message)
+		}
+	}
+
+	writer.Flush()
+
+	rolledBack := ""
+	if report.RolledBack {
+		rolledBack = "; rolled back"
+	}
+
+	fmt.Fprintf(
+		output,
+		"%d created, %d updated, %d failed%s\n",
+		report.Created,
+		report.Updated,
+		report.Failed,
+		rolledBack,
+	)
+}
+
+// orDash text, or "-" if there is none.
+func orDash(text *string) string {
+	if text == nil {
+		return "-"
+	}
+
+	return *text
+}
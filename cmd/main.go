@@ -3,6 +3,9 @@ package main
 import (
 	"RocketContainer.go/graph"
 	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/expiry"
+	"RocketContainer.go/internal/trash"
+	"context"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
@@ -13,9 +16,16 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
-const defaultPort = "8080"
+const (
+	defaultPort          = "8080"
+	defaultSweepAction   = "event"
+	defaultSweepInterval = 5 * time.Minute
+)
 
 func main() {
 	logger := zap.Must(zap.NewProduction()).Named("RocketContainer")
@@ -27,18 +37,50 @@ func main() {
 		logger.Fatal("failed to load .env file", zap.Error(dotenvErr))
 	}
 
-	data.InitDb()
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(logger, os.Args[2:])
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(logger, os.Args[2:])
+
+		return
+	}
+
+	store := data.InitDb()
+
+	sweeper := newSweeper(logger, store)
+	if sweeper != nil {
+		go sweeper.Run(context.Background())
+	}
+
+	if purger := newPurger(logger, store); purger != nil {
+		go purger.Run(context.Background())
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = defaultPort
 	}
 
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	resolver := graph.Resolver{
+		AcceptNumericIDs: acceptNumericIDs(logger),
+		AdminToken:       os.Getenv("ADMIN_TOKEN"),
+		Store:            store,
+		Sweeper:          sweeper,
+	}
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &resolver}))
+
+	// Outside development, clients only see a correlation ID for internal errors; the details are in the log.
+	srv.SetErrorPresenter(graph.NewErrorPresenter(logger.Named("graphql"), os.Getenv("APP_ENV") == "development"))
+	srv.SetRecoverFunc(graph.Recover)
 
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
@@ -50,7 +92,13 @@ func main() {
 	)
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle(
+		"/query",
+		graph.ActorMiddleware(
+			actors(logger, resolver.AdminToken),
+			graph.TokenMiddleware(graph.LoaderMiddleware(store, srv)),
+		),
+	)
 
 	logger.Info("connect to http://localhost:/ for GraphQL playground", zap.String("port", port))
 	httpErr := http.ListenAndServe(":"+port, nil)
@@ -58,3 +106,102 @@ func main() {
 		logger.Fatal("failed to start HTTP server", zap.Error(httpErr))
 	}
 }
+
+// acceptNumericIDs whether ACCEPT_NUMERIC_IDS allows bare primary keys in place of global IDs. Defaults to true.
+func acceptNumericIDs(logger *zap.Logger) bool {
+	text := os.Getenv("ACCEPT_NUMERIC_IDS")
+	if text == "" {
+		return true
+	}
+
+	accept, err := strconv.ParseBool(text)
+	if err != nil {
+		logger.Fatal("invalid ACCEPT_NUMERIC_IDS", zap.String("value", text), zap.Error(err))
+	}
+
+	return accept
+}
+
+// actors credentials of the actors of requests: adminToken, the header named by ACTOR_HEADER, and the bearer tokens
+// of ACTOR_TOKENS, a comma-separated list of name=token pairs.
+func actors(logger *zap.Logger, adminToken string) graph.Actors {
+	actors := graph.Actors{AdminToken: adminToken, Header: os.Getenv("ACTOR_HEADER"), Tokens: map[string]string{}}
+
+	text := os.Getenv("ACTOR_TOKENS")
+	if text == "" {
+		return actors
+	}
+
+	names := map[string]string{}
+
+	for _, pair := range strings.Split(text, ",") {
+		name, token, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" || token == "" {
+			logger.Fatal("invalid ACTOR_TOKENS: each entry must be name=token", zap.String("name", name))
+		}
+
+		if other, ok := names[token]; ok || token == adminToken {
+			logger.Fatal(
+				"invalid ACTOR_TOKENS: tokens must be unique",
+				zap.String("name", name),
+				zap.String("other", other),
+			)
+		}
+
+		actors.Tokens[name] = token
+		names[token] = name
+	}
+
+	return actors
+}
+
+// newPurger create the purger configured by TRASH_RETENTION_DAYS, or nil if it is unset or 0 and deleted records are
+// kept until purged explicitly.
+func newPurger(logger *zap.Logger, store data.Store) *trash.Purger {
+	text := os.Getenv("TRASH_RETENTION_DAYS")
+	if text == "" || text == "0" {
+		logger.Info("purger disabled")
+
+		return nil
+	}
+
+	days, err := strconv.Atoi(text)
+	if err != nil || days < 0 {
+		logger.Fatal("invalid TRASH_RETENTION_DAYS", zap.String("days", text), zap.Error(err))
+	}
+
+	return trash.NewPurger(store, logger.Named("trash"), time.Duration(days)*24*time.Hour)
+}
+
+// newSweeper create the expiry sweeper configured by EXPIRY_SWEEP_ACTION and EXPIRY_SWEEP_INTERVAL, or nil if the
+// interval is 0.
+func newSweeper(logger *zap.Logger, store data.Store) *expiry.Sweeper {
+	interval := defaultSweepInterval
+
+	if text := os.Getenv("EXPIRY_SWEEP_INTERVAL"); text != "" {
+		parsed, err := time.ParseDuration(text)
+		if err != nil || parsed < 0 {
+			logger.Fatal("invalid EXPIRY_SWEEP_INTERVAL", zap.String("interval", text), zap.Error(err))
+		}
+
+		interval = parsed
+	}
+
+	if interval == 0 {
+		logger.Info("expiry sweeper disabled")
+
+		return nil
+	}
+
+	actionName := os.Getenv("EXPIRY_SWEEP_ACTION")
+	if actionName == "" {
+		actionName = defaultSweepAction
+	}
+
+	action, err := expiry.ParseAction(actionName)
+	if err != nil {
+		logger.Fatal("invalid EXPIRY_SWEEP_ACTION", zap.Error(err))
+	}
+
+	return expiry.NewSweeper(store, logger.Named("expiry"), action, interval)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/migrations"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate run the migrate subcommand: "up" applies pending migrations, "down [steps]" reverts the latest steps
+// (default 1) migrations, and "status" lists every migration.
+func runMigrate(logger *zap.Logger, args []string) {
+	if len(args) == 0 {
+		logger.Fatal(migrateUsage)
+	}
+
+	db, dbErr := data.OpenDb()
+	if dbErr != nil {
+		logger.Fatal("failed to connect to database", zap.Error(dbErr))
+	}
+
+	migrator, migratorErr := migrations.New(db, logger.Named("migrations"))
+	if migratorErr != nil {
+		logger.Fatal("failed to load migrations", zap.Error(migratorErr))
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Fatal("failed to apply migrations", zap.Error(err))
+		}
+
+		logger.Info("applied migrations", zap.Int("count", applied))
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 {
+				logger.Fatal(migrateUsage, zap.String("steps", args[1]))
+			}
+
+			steps = parsed
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Fatal("failed to revert migrations", zap.Error(err))
+		}
+
+		logger.Info("reverted migrations", zap.Int("count", reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Fatal("failed to read migration status", zap.Error(err))
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		writer.Flush()
+	default:
+		logger.Fatal(migrateUsage, zap.String("command", args[0]))
+	}
+}
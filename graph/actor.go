@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"RocketContainer.go/internal/data"
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminActor actor of the changes made with the admin token.
+const AdminActor = "admin"
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Actors credentials that tell ActorMiddleware who makes the changes of a request.
+type Actors struct {
+	// AdminToken admin token, whose changes are made by AdminActor.
+	AdminToken string
+	// Header request header naming the actor of requests without a known bearer token, as set by a trusted
+	// authenticating proxy in front of the service, which must drop the header from client requests; "" trusts none.
+	Header string
+	// Tokens bearer tokens of the callers, by actor name.
+	Tokens map[string]string
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// ActorMiddleware attribute the changes made by each request handled by next to its actor according to actors: the
+// caller whose bearer token it carries, if any, and otherwise the actor named by the trusted header. The changes of
+// other requests are anonymous.
+func ActorMiddleware(actors Actors, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if actor := actors.actor(request); actor != "" {
+			request = request.WithContext(data.WithActor(request.Context(), actor))
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// actor who makes the changes of request, or "" if it is not authenticated.
+func (actors Actors) actor(request *http.Request) string {
+	if token, ok := bearerToken(request); ok && token != "" {
+		for name, actorToken := range actors.Tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(actorToken)) == 1 {
+				return name
+			}
+		}
+
+		if isAdminToken(token, actors.AdminToken) {
+			return AdminActor
+		}
+	}
+
+	if actors.Header == "" {
+		return ""
+	}
+
+	return request.Header.Get(actors.Header)
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// bearerPrefix prefix of the Authorization header of requests that carry a bearer token.
+const bearerPrefix = "Bearer "
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// tokenKey context key of the bearer token of a request.
+type tokenKey struct{}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// TokenMiddleware make the bearer token in the Authorization header of each request handled by next, if any,
+// available to the resolvers that require one.
+func TokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if token, ok := bearerToken(request); ok {
+			request = request.WithContext(context.WithValue(request.Context(), tokenKey{}, token))
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// authorizeAdmin check that the request of ctx carries the admin token, which field requires.
+func (r *Resolver) authorizeAdmin(ctx context.Context, field string) error {
+	if r.AdminToken == "" {
+		return apperr.Errorf(apperr.Unauthorized, "%s is disabled; set ADMIN_TOKEN to enable it", field)
+	}
+
+	token, _ := ctx.Value(tokenKey{}).(string)
+	if !isAdminToken(token, r.AdminToken) {
+		return apperr.Errorf(apperr.Unauthorized, "%s requires the admin token as a bearer token", field)
+	}
+
+	return nil
+}
+
+// bearerToken bearer token in the Authorization header of request, or ok is false if it has none.
+func bearerToken(request *http.Request) (token string, ok bool) {
+	token, ok = strings.CutPrefix(request.Header.Get("Authorization"), bearerPrefix)
+
+	return strings.TrimSpace(token), ok
+}
+
+// isAdminToken whether token is adminToken, which must be set.
+func isAdminToken(token string, adminToken string) bool {
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
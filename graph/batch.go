@@ -0,0 +1,193 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/data"
+	"errors"
+	"slices"
+	"strconv"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// contentIndexes input indexes of the content of a container that is passed to the data layer, indexed like the
+// container's Videos, their Assets, and its own Assets.
+type contentIndexes struct {
+	assets      []int
+	videoAssets [][]int
+	videos      []int
+}
+
+/* ****************************************************************************************************************** *
+ *                                                  Content indexes                                                   *
+ * ****************************************************************************************************************** */
+
+// errors errs, the errors of creating the content of a container, attributed to the input fields of the content.
+func (indexes contentIndexes) errors(errs data.ContentErrors) error {
+	if errs.Container != nil {
+		return inField(errs.Container, "input")
+	}
+
+	var all []error
+
+	for k, i := range indexes.videos {
+		path := []string{"input", "videos", strconv.Itoa(i)}
+		all = append(all, inField(errs.Videos[k], path...))
+
+		for l, j := range indexes.videoAssets[k] {
+			assetPath := slices.Concat(path, []string{"assets", strconv.Itoa(j)})
+			all = append(all, inField(errs.VideoAssets[k][l], assetPath...))
+		}
+	}
+
+	for k, i := range indexes.assets {
+		all = append(all, inField(errs.Assets[k], "input", "assets", strconv.Itoa(i)))
+	}
+
+	return errors.Join(all...)
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// createBatch convert each of inputs to a record with validate, whose problems are attributed to the input fields
+// under ["input", index], then create the records with create, whose errors are attributed to the input at that path,
+// or to its reference fields like inReferenceField. Unless continueOnError, any problem creates none of them;
+// otherwise only the inputs with problems are skipped. Returns the records created, indexed like inputs with nil for
+// those that were not, and every problem, joined.
+func createBatch[I any, T any](
+	inputs []I,
+	continueOnError bool,
+	validate func(input I, path ...string) (T, error),
+	create func(records []T) ([]error, error),
+) ([]*T, error) {
+	created := make([]*T, len(inputs))
+	records := make([]T, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+
+	var errs []error
+
+	for i, input := range inputs {
+		record, err := validate(input, "input", strconv.Itoa(i))
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		records = append(records, record)
+		indexes = append(indexes, i)
+	}
+
+	if len(errs) > 0 && !continueOnError {
+		return created, errors.Join(errs...)
+	}
+
+	recordErrs, err := create(records)
+	if err != nil {
+		return created, err
+	}
+
+	for k, i := range indexes {
+		path := []string{"input", strconv.Itoa(i)}
+		errs = append(errs, inReferenceField(
+			recordErrs[k],
+			slices.Concat(path, []string{"containerID"}),
+			slices.Concat(path, []string{"videoID"}),
+			func(err error) error { return inField(err, path...) },
+		))
+	}
+
+	err = errors.Join(errs...)
+	if err != nil && !continueOnError {
+		return created, err
+	}
+
+	for k, i := range indexes {
+		if recordErrs[k] == nil {
+			created[i] = &records[k]
+		}
+	}
+
+	return created, err
+}
+
+// toContainerWithContent database container, with its videos, their assets, and its own assets, for input, along with
+// the input indexes of its content, and every problem with its content, attributed to its field. Unless
+// continueOnError, content with problems is kept; otherwise it is left out, along with the assets of the videos left
+// out, which fail with data.ErrOwnerNotCreated.
+func toContainerWithContent(
+	input model.NewContainerWithContent,
+	continueOnError bool,
+) (data.Container, contentIndexes, error) {
+	container := data.Container{
+		Description: input.Description,
+		Name:        input.Name,
+	}
+
+	var errs []error
+
+	var indexes contentIndexes
+
+	for i, videoInput := range input.Videos {
+		path := []string{"input", "videos", strconv.Itoa(i)}
+		videoErr := validateInput(videoInput, path...)
+		errs = append(errs, videoErr)
+
+		video := data.Video{
+			Description:    videoInput.Description,
+			ExpirationDate: videoInput.ExpirationDate,
+			PlaybackURL:    videoInput.PlaybackURL,
+			Title:          videoInput.Title,
+			VideoType:      data.VideoType(videoInput.VideoType),
+		}
+
+		var assetIndexes []int
+
+		for j, assetInput := range videoInput.Assets {
+			assetPath := slices.Concat(path, []string{"assets", strconv.Itoa(j)})
+
+			assetErr := validateInput(assetInput, assetPath...)
+			if assetErr == nil && videoErr != nil && continueOnError {
+				assetErr = inField(data.ErrOwnerNotCreated, assetPath...)
+			}
+
+			errs = append(errs, assetErr)
+
+			if assetErr == nil || !continueOnError {
+				video.Assets = append(video.Assets, toContentAsset(*assetInput))
+				assetIndexes = append(assetIndexes, j)
+			}
+		}
+
+		if videoErr == nil || !continueOnError {
+			container.Videos = append(container.Videos, video)
+			indexes.videos = append(indexes.videos, i)
+			indexes.videoAssets = append(indexes.videoAssets, assetIndexes)
+		}
+	}
+
+	for i, assetInput := range input.Assets {
+		err := validateInput(assetInput, "input", "assets", strconv.Itoa(i))
+		errs = append(errs, err)
+
+		if err == nil || !continueOnError {
+			container.Assets = append(container.Assets, toContentAsset(*assetInput))
+			indexes.assets = append(indexes.assets, i)
+		}
+	}
+
+	return container, indexes, errors.Join(errs...)
+}
+
+// toContentAsset database asset for an asset of a container being created.
+func toContentAsset(input model.NewContentAsset) data.Asset {
+	return data.Asset{
+		AssetType: data.AssetType(input.AssetType),
+		Name:      input.Name,
+		URL:       input.URL,
+	}
+}
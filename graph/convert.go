@@ -0,0 +1,420 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
+	"RocketContainer.go/internal/data"
+	"encoding/json"
+	"gorm.io/gorm"
+	"maps"
+	"slices"
+	"time"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// boolValue value of an optional Boolean argument, defaulting to false.
+func boolValue(value *bool) bool {
+	return value != nil && *value
+}
+
+// intValue value of an optional Int argument.
+func intValue(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+
+	converted := int(*value)
+
+	return &converted
+}
+
+// isDescending whether an optional OrderDirection argument is DESC.
+func isDescending(direction *model.OrderDirection) bool {
+	return direction != nil && *direction == model.OrderDirectionDesc
+}
+
+// newPage data layer page for Relay pagination arguments.
+func newPage(first *int32, after *string, last *int32, before *string) data.Page {
+	return data.Page{After: after, Before: before, First: intValue(first), Last: intValue(last)}
+}
+
+// stringValue value of an optional String argument, defaulting to empty.
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+// toAssetConnection GraphQL connection for a page of assets.
+func toAssetConnection(connection data.Connection[data.Asset]) *model.AssetConnection {
+	edges := make([]*model.AssetEdge, 0, len(connection.Edges))
+
+	for _, edge := range connection.Edges {
+		edges = append(edges, &model.AssetEdge{Cursor: edge.Cursor, Node: toModelAsset(edge.Node)})
+	}
+
+	return &model.AssetConnection{
+		Edges:      edges,
+		PageInfo:   toPageInfo(connection),
+		TotalCount: int32(connection.TotalCount),
+	}
+}
+
+// toAssetFilter data layer filter for an optional AssetFilter argument.
+func toAssetFilter(filter *model.AssetFilter, includeExpired *bool) data.AssetFilter {
+	assetFilter := data.AssetFilter{IncludeExpired: boolValue(includeExpired)}
+
+	if filter == nil {
+		return assetFilter
+	}
+
+	if filter.AssetType != nil {
+		assetFilter.AssetType = data.AssetType(*filter.AssetType)
+	}
+
+	assetFilter.CreatedAfter = filter.CreatedAfter
+	assetFilter.CreatedBefore = filter.CreatedBefore
+	assetFilter.Name = stringValue(filter.Name)
+	assetFilter.UpdatedAfter = filter.UpdatedAfter
+	assetFilter.UpdatedBefore = filter.UpdatedBefore
+
+	return assetFilter
+}
+
+// toAssetFilterOfType data layer filter for an optional AssetFilter argument of a query returning only assetType.
+func toAssetFilterOfType(
+	filter *model.AssetFilter,
+	includeExpired *bool,
+	assetType data.AssetType,
+) (data.AssetFilter, error) {
+	assetFilter := toAssetFilter(filter, includeExpired)
+
+	if assetFilter.AssetType != "" && assetFilter.AssetType != assetType {
+		return assetFilter, apperr.Errorf(apperr.Validation, "filter.assetType must be %s or omitted", assetType)
+	}
+
+	assetFilter.AssetType = assetType
+
+	return assetFilter, nil
+}
+
+// toAssetOrder data layer order for an optional AssetOrder argument.
+func toAssetOrder(order *model.AssetOrder) data.Order {
+	if order == nil {
+		return data.Order{}
+	}
+
+	return data.Order{Descending: isDescending(order.Direction), Field: data.OrderField(order.Field)}
+}
+
+// toAuditEventConnection GraphQL connection for a page of audit events.
+func toAuditEventConnection(connection data.Connection[data.AuditEvent]) *model.AuditEventConnection {
+	edges := make([]*model.AuditEventEdge, 0, len(connection.Edges))
+
+	for _, edge := range connection.Edges {
+		edges = append(edges, &model.AuditEventEdge{Cursor: edge.Cursor, Node: toModelAuditEvent(edge.Node)})
+	}
+
+	return &model.AuditEventConnection{
+		Edges:      edges,
+		PageInfo:   toPageInfo(connection),
+		TotalCount: int32(connection.TotalCount),
+	}
+}
+
+// toContainerConnection GraphQL connection for a page of containers.
+func toContainerConnection(connection data.Connection[data.Container]) *model.ContainerConnection {
+	edges := make([]*model.ContainerEdge, 0, len(connection.Edges))
+
+	for _, edge := range connection.Edges {
+		edges = append(edges, &model.ContainerEdge{Cursor: edge.Cursor, Node: toModelContainer(edge.Node)})
+	}
+
+	return &model.ContainerConnection{
+		Edges:      edges,
+		PageInfo:   toPageInfo(connection),
+		TotalCount: int32(connection.TotalCount),
+	}
+}
+
+// toDeletedAt deletion time of a soft-deleted record, or nil if it has not been deleted.
+func toDeletedAt(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+
+	return &deletedAt.Time
+}
+
+// toDeletePolicy data layer delete policy for an optional delete policy argument, which cascades by default.
+func toDeletePolicy[T ~string](policy *T) data.DeletePolicy {
+	if policy == nil {
+		return data.Cascade
+	}
+
+	return data.DeletePolicy(*policy)
+}
+
+// toIntegrityIssues GraphQL integrity issues for database integrity issues of nodeType objects.
+func toIntegrityIssues(issues []data.IntegrityIssue, nodeType string) []*model.IntegrityIssue {
+	result := make([]*model.IntegrityIssue, 0, len(issues))
+
+	for _, issue := range issues {
+		referencedType := videoNode
+		if issue.Problem == data.ContainerDeleted || issue.Problem == data.ContainerMissing {
+			referencedType = containerNode
+		}
+
+		result = append(result, &model.IntegrityIssue{
+			ID:           encodeID(nodeType, issue.ID),
+			Problem:      model.IntegrityProblem(issue.Problem),
+			ReferencedID: encodeID(referencedType, issue.ReferencedID),
+		})
+	}
+
+	return result
+}
+
+// toIntegrityReport GraphQL integrity report for a database integrity report.
+func toIntegrityReport(report data.IntegrityReport) *model.IntegrityReport {
+	return &model.IntegrityReport{
+		Assets: toIntegrityIssues(report.Assets, assetNode),
+		Videos: toIntegrityIssues(report.Videos, videoNode),
+	}
+}
+
+// toJSON GraphQL JSON for optional JSON text.
+func toJSON(text *string) model.JSON {
+	if text == nil {
+		return nil
+	}
+
+	return model.JSON(*text)
+}
+
+// toJSONValue JSON value for marshalled JSON, or nil if it is null.
+func toJSONValue(raw []byte) model.JSON {
+	if string(raw) == "null" {
+		return nil
+	}
+
+	return raw
+}
+
+// toModelAsset GraphQL asset for a database asset.
+func toModelAsset(asset data.Asset) *model.Asset {
+	return &model.Asset{
+		AssetType:   model.AssetType(asset.AssetType),
+		ContainerID: asset.ContainerID,
+		DeletedAt:   toDeletedAt(asset.DeletedAt),
+		ExternalID:  asset.ExternalID,
+		ID:          encodeID(assetNode, asset.ID),
+		Name:        asset.Name,
+		URL:         asset.URL,
+		Version:     int32(asset.Version),
+		VideoID:     asset.VideoID,
+	}
+}
+
+// toModelAuditEvent GraphQL audit event for a database audit event.
+func toModelAuditEvent(event data.AuditEvent) *model.AuditEvent {
+	return &model.AuditEvent{
+		Actor:      event.Actor,
+		After:      toJSON(event.After),
+		Before:     toJSON(event.Before),
+		EntityID:   encodeID(event.EntityType, event.EntityID),
+		EntityType: event.EntityType,
+		OccurredAt: event.OccurredAt,
+		Operation:  model.AuditOperation(event.Operation),
+	}
+}
+
+// toModelContainer GraphQL container for a database container. Its assets and videos are resolved separately.
+func toModelContainer(container data.Container) *model.Container {
+	return &model.Container{
+		DeletedAt:   toDeletedAt(container.DeletedAt),
+		Description: container.Description,
+		ExternalID:  container.ExternalID,
+		ID:          encodeID(containerNode, container.ID),
+		Key:         container.ID,
+		Name:        container.Name,
+		Version:     int32(container.Version),
+	}
+}
+
+// toModelVideo GraphQL video for a database video. Its assets are resolved separately.
+func toModelVideo(video data.Video) *model.Video {
+	return &model.Video{
+		ArchivedAt:     video.ArchivedAt,
+		ContainerID:    video.ContainerID,
+		DeletedAt:      toDeletedAt(video.DeletedAt),
+		Description:    video.Description,
+		ExpirationDate: video.ExpirationDate,
+		ExternalID:     video.ExternalID,
+		ID:             encodeID(videoNode, video.ID),
+		Key:            video.ID,
+		PlaybackURL:    video.PlaybackURL,
+		Title:          video.Title,
+		Version:        int32(video.Version),
+		VideoType:      model.VideoType(video.VideoType),
+	}
+}
+
+// toModelVideoRevision GraphQL video revision for a database video revision.
+func toModelVideoRevision(revision data.VideoRevision) *model.VideoRevision {
+	return &model.VideoRevision{
+		Actor:          revision.Actor,
+		ArchivedAt:     revision.ArchivedAt,
+		ContainerID:    encodeID(containerNode, revision.ContainerID),
+		CreatedAt:      revision.CreatedAt,
+		DeletedAt:      revision.DeletedAt,
+		Description:    revision.Description,
+		ExpirationDate: revision.ExpirationDate,
+		Operation:      model.AuditOperation(revision.Operation),
+		PlaybackURL:    revision.PlaybackURL,
+		Revision:       int32(revision.Revision),
+		Title:          revision.Title,
+		VideoType:      model.VideoType(revision.VideoType),
+	}
+}
+
+// toPageInfo GraphQL page info for a page of results.
+func toPageInfo[T any](connection data.Connection[T]) *model.PageInfo {
+	pageInfo := model.PageInfo{
+		HasNextPage:     connection.HasNextPage,
+		HasPreviousPage: connection.HasPreviousPage,
+	}
+
+	if len(connection.Edges) > 0 {
+		pageInfo.StartCursor = &connection.Edges[0].Cursor
+		pageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return &pageInfo
+}
+
+// toSearchResult GraphQL search result for a database search result.
+func toSearchResult(result data.SearchResult) *model.SearchResult {
+	searchResult := model.SearchResult{Score: result.Score, Snippet: result.Snippet}
+
+	if result.Video != nil {
+		searchResult.Node = toModelVideo(*result.Video)
+	} else {
+		searchResult.Node = toModelAsset(*result.Asset)
+	}
+
+	return &searchResult
+}
+
+// toUTC time in UTC, or nil if there is none.
+func toUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+
+	return &utc
+}
+
+// toVideoConnection GraphQL connection for a page of videos.
+func toVideoConnection(connection data.Connection[data.Video]) *model.VideoConnection {
+	edges := make([]*model.VideoEdge, 0, len(connection.Edges))
+
+	for _, edge := range connection.Edges {
+		edges = append(edges, &model.VideoEdge{Cursor: edge.Cursor, Node: toModelVideo(edge.Node)})
+	}
+
+	return &model.VideoConnection{
+		Edges:      edges,
+		PageInfo:   toPageInfo(connection),
+		TotalCount: int32(connection.TotalCount),
+	}
+}
+
+// toVideoFieldChanges GraphQL changes to the fields of a video from one revision to another, ordered by field name.
+func toVideoFieldChanges(from data.VideoRevision, to data.VideoRevision) []*model.VideoFieldChange {
+	fromFields, toFields := toVideoRevisionFields(from), toVideoRevisionFields(to)
+	changes := make([]*model.VideoFieldChange, 0, len(fromFields))
+
+	for _, field := range slices.Sorted(maps.Keys(fromFields)) {
+		fromValue, _ := json.Marshal(fromFields[field])
+		toValue, _ := json.Marshal(toFields[field])
+
+		if string(fromValue) != string(toValue) {
+			changes = append(changes, &model.VideoFieldChange{
+				Field: field,
+				From:  toJSONValue(fromValue),
+				To:    toJSONValue(toValue),
+			})
+		}
+	}
+
+	return changes
+}
+
+// toVideoFilter data layer filter for an optional VideoFilter argument.
+func toVideoFilter(filter *model.VideoFilter, includeExpired *bool) data.VideoFilter {
+	videoFilter := data.VideoFilter{IncludeExpired: boolValue(includeExpired)}
+
+	if filter == nil {
+		return videoFilter
+	}
+
+	if filter.VideoType != nil {
+		videoFilter.VideoType = data.VideoType(*filter.VideoType)
+	}
+
+	videoFilter.CreatedAfter = filter.CreatedAfter
+	videoFilter.CreatedBefore = filter.CreatedBefore
+	videoFilter.ExpiresAfter = filter.ExpiresAfter
+	videoFilter.ExpiresBefore = filter.ExpiresBefore
+	videoFilter.Title = stringValue(filter.Title)
+	videoFilter.UpdatedAfter = filter.UpdatedAfter
+	videoFilter.UpdatedBefore = filter.UpdatedBefore
+
+	return videoFilter
+}
+
+// toVideoOrder data layer order for an optional VideoOrder argument.
+func toVideoOrder(order *model.VideoOrder) data.Order {
+	if order == nil {
+		return data.Order{}
+	}
+
+	return data.Order{Descending: isDescending(order.Direction), Field: data.OrderField(order.Field)}
+}
+
+// toVideoRevisionConnection GraphQL connection for a page of video revisions.
+func toVideoRevisionConnection(connection data.Connection[data.VideoRevision]) *model.VideoRevisionConnection {
+	edges := make([]*model.VideoRevisionEdge, 0, len(connection.Edges))
+
+	for _, edge := range connection.Edges {
+		edges = append(edges, &model.VideoRevisionEdge{Cursor: edge.Cursor, Node: toModelVideoRevision(edge.Node)})
+	}
+
+	return &model.VideoRevisionConnection{
+		Edges:      edges,
+		PageInfo:   toPageInfo(connection),
+		TotalCount: int32(connection.TotalCount),
+	}
+}
+
+// toVideoRevisionFields fields of a video revision that diffs compare, by GraphQL field name, with times in UTC.
+func toVideoRevisionFields(revision data.VideoRevision) map[string]any {
+	return map[string]any{
+		"archivedAt":     toUTC(revision.ArchivedAt),
+		"containerID":    encodeID(containerNode, revision.ContainerID),
+		"deletedAt":      toUTC(revision.DeletedAt),
+		"description":    revision.Description,
+		"expirationDate": toUTC(revision.ExpirationDate),
+		"playbackUrl":    revision.PlaybackURL,
+		"title":          revision.Title,
+		"videoType":      revision.VideoType,
+	}
+}
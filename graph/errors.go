@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
+	"RocketContainer.go/internal/data"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+	"runtime/debug"
+)
+
+// internalMessage message of internal errors when their details are hidden.
+const internalMessage = "internal server error"
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// fieldError error caused by the value of one input field.
+type fieldError struct {
+	err   error
+	field []string
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// NewErrorPresenter error presenter setting the extensions.code of resolver errors to their apperr.Kind. Internal
+// errors are logged with their cause and a correlation ID, which is also returned to the client, and unless
+// exposeInternal their message is replaced with a generic one.
+func NewErrorPresenter(logger *zap.Logger, exposeInternal bool) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		presented := graphql.DefaultErrorPresenter(ctx, err)
+
+		// Requests that do not match the schema are rejected with GraphQL errors of their own, which wrap no other
+		// error and are safe to show as they are.
+		if presented.Err == nil {
+			return presented
+		}
+
+		kind := apperr.KindOf(err)
+		if presented.Extensions == nil {
+			presented.Extensions = map[string]interface{}{}
+		}
+
+		presented.Extensions["code"] = string(kind)
+
+		if kind == apperr.Internal {
+			correlationID := newCorrelationID()
+			presented.Extensions["correlationID"] = correlationID
+
+			logger.Error(
+				"Internal error",
+				zap.String("correlationID", correlationID),
+				zap.String("path", presented.Path.String()),
+				zap.Error(cause(err)),
+			)
+
+			if !exposeInternal {
+				presented.Message = internalMessage
+			}
+		}
+
+		return presented
+	}
+}
+
+// Recover recover func reporting a panic in a resolver as an internal error, with the stack trace in its cause.
+func Recover(ctx context.Context, panicked interface{}) error {
+	return apperr.Wrap(apperr.Internal, fmt.Errorf("panic: %v\n%s", panicked, debug.Stack()), internalMessage)
+}
+
+func (err *fieldError) Error() string {
+	return err.err.Error()
+}
+
+func (err *fieldError) Unwrap() error {
+	return err.err
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// cause the apperr.Error in err's tree, which logs its cause, or err if there is none.
+func cause(err error) error {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return err
+}
+
+// flatten errors joined in err, however deeply.
+func flatten(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, flatten(err)...)
+	}
+
+	return errs
+}
+
+// inField attribute err, unless it is nil, to the input field at path field.
+func inField(err error, field ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &fieldError{err: err, field: field}
+}
+
+// inReferenceField attribute each error joined in err, from writing a video or asset, to the input field of the
+// reference it is about: containerField for a missing or deleted container, and videoField for a missing video or one
+// in another container. The other errors are attributed with otherwise, unless it is nil.
+func inReferenceField(err error, containerField []string, videoField []string, otherwise func(error) error) error {
+	var errs []error
+
+	for _, err := range flatten(err) {
+		switch {
+		case errors.Is(err, data.ErrMissingContainer), errors.Is(err, data.ErrDeletedOwner):
+			err = inField(err, containerField...)
+		case errors.Is(err, data.ErrMissingVideo), errors.Is(err, data.ErrVideoContainerMismatch):
+			err = inField(err, videoField...)
+		case otherwise != nil:
+			err = otherwise(err)
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// inUpdatedField attribute err from updating the record matching input.id to input.version if it is a version
+// conflict, and to input.id otherwise.
+func inUpdatedField(err error) error {
+	if errors.Is(err, data.ErrVersionConflict) {
+		return inField(err, "input", "version")
+	}
+
+	return inField(err, "input", "id")
+}
+
+// newCorrelationID random ID tying an error reported to a client to its log entry.
+func newCorrelationID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// toUserErrors user errors reporting err, which may join several errors, however deeply. If any of them is not a
+// problem with the input, err is returned instead, to be reported as a GraphQL error.
+func toUserErrors(err error) ([]*model.UserError, error) {
+	errs := flatten(err)
+	userErrors := make([]*model.UserError, 0, len(errs))
+
+	for _, err := range errs {
+		userError := model.UserError{Message: err.Error()}
+
+		switch apperr.KindOf(err) {
+		case apperr.Conflict:
+			userError.Code = model.UserErrorCodeConflict
+		case apperr.NotFound:
+			userError.Code = model.UserErrorCodeNotFound
+		case apperr.Validation:
+			userError.Code = model.UserErrorCodeInvalidValue
+			if errors.Is(err, errInvalidID) {
+				userError.Code = model.UserErrorCodeInvalidID
+			}
+		default:
+			return nil, err
+		}
+
+		var inField *fieldError
+		if errors.As(err, &inField) {
+			userError.Field = inField.field
+		}
+
+		userErrors = append(userErrors, &userError)
+	}
+
+	return userErrors, nil
+}
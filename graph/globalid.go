@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node types, which prefix the global IDs of their objects.
+const (
+	assetNode     = "Asset"
+	containerNode = "Container"
+	videoNode     = "Video"
+)
+
+// errInvalidID returned for IDs that are malformed or identify an object of another type.
+var errInvalidID = apperr.New(apperr.Validation, "invalid ID")
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// decodeID node type and primary key of a global ID.
+func decodeID(id string) (string, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w %q", errInvalidID, id)
+	}
+
+	nodeType, keyText, found := strings.Cut(string(raw), ":")
+	key, err := strconv.ParseUint(keyText, 10, 0)
+
+	if !found || err != nil || key == 0 {
+		return "", 0, fmt.Errorf("%w %q", errInvalidID, id)
+	}
+
+	return nodeType, uint(key), nil
+}
+
+// encodeID opaque global ID of the nodeType object with primary key key.
+func encodeID(nodeType string, key uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(nodeType + ":" + strconv.FormatUint(uint64(key), 10)))
+}
+
+// optionalKey like primaryKey, except that "0" always stands for no object and is returned as 0.
+func (r *Resolver) optionalKey(nodeType string, id string) (uint, error) {
+	if id == "0" {
+		return 0, nil
+	}
+
+	return r.primaryKey(nodeType, id)
+}
+
+// primaryKey primary key of the nodeType object identified by id. While the resolver accepts numeric IDs, id may
+// also be the bare primary key.
+func (r *Resolver) primaryKey(nodeType string, id string) (uint, error) {
+	if r.AcceptNumericIDs {
+		if key, err := strconv.ParseUint(id, 10, 0); err == nil && key != 0 {
+			return uint(key), nil
+		}
+	}
+
+	idType, key, err := decodeID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if idType != nodeType {
+		return 0, fmt.Errorf("%w: %q is not a %s ID", errInvalidID, id, nodeType)
+	}
+
+	return key, nil
+}
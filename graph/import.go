@@ -0,0 +1,504 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
+	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/importer"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// typeField field of import records naming the type of record they import.
+const typeField = "type"
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// catalogIndexes indexes of the records of an import file that are imported as each entry of the lists of a catalog.
+type catalogIndexes struct {
+	assets     []int
+	containers []int
+	videos     []int
+}
+
+// catalogRecord record of an import file, decoded into what it imports. Records that cannot be decoded keep their
+// node type and external ID, if they have valid ones.
+type catalogRecord struct {
+	asset      *data.CatalogAsset
+	container  *data.Container
+	externalID *string
+	nodeType   string
+	video      *data.CatalogVideo
+}
+
+// containerReference field of import records referring to their container by external ID.
+type containerReference struct {
+	ContainerExternalID *string `json:"containerExternalID"`
+}
+
+// importKey external ID of a nodeType record of an import.
+type importKey struct {
+	externalID string
+	nodeType   string
+}
+
+// videoReference field of import records referring to their video by external ID.
+type videoReference struct {
+	VideoExternalID *string `json:"videoExternalID"`
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// Import import the records read from an import file and report what became of each. Records that cannot be
+// imported are reported in their lines; unless options.ContinueOnError, they roll back the whole import. An error is
+// returned only if the import itself fails.
+func (r *Resolver) Import(
+	ctx context.Context,
+	rows []importer.Row,
+	options data.ImportOptions,
+) (*model.ImportReport, error) {
+	records := make([]catalogRecord, len(rows))
+	errs := make([]error, len(rows))
+
+	for i, row := range rows {
+		if row.Err != nil {
+			errs[i] = row.Err
+		} else {
+			records[i], errs[i] = r.decodeRecord(row.Fields)
+		}
+	}
+
+	checkExternalIDs(rows, records, errs)
+
+	// The records that are valid are still imported, and rolled back, to report what importing them would do.
+	if !options.ContinueOnError && slices.ContainsFunc(errs, isError) {
+		options.DryRun = true
+	}
+
+	catalog, indexes := toCatalog(records, errs)
+
+	catalogResults, err := r.Store.ImportCatalog(ctx, &catalog, options)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]data.ImportResult, len(rows))
+	ids := make([]uint, len(rows))
+
+	for k, i := range indexes.containers {
+		results[i], ids[i] = catalogResults.Containers[k], catalog.Containers[k].ID
+	}
+
+	for k, i := range indexes.videos {
+		results[i], ids[i] = catalogResults.Videos[k], catalog.Videos[k].ID
+	}
+
+	for k, i := range indexes.assets {
+		results[i], ids[i] = catalogResults.Assets[k], catalog.Assets[k].ID
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			errs[i] = records[i].attributeError(result.Err)
+			if errors.Is(result.Err, data.ErrDeletedExternalID) {
+				errs[i] = inField(result.Err, "externalID")
+			}
+		}
+	}
+
+	return toImportReport(rows, records, errs, results, ids, options)
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// attributeError attribute err, from importing the record, to the field by which the record refers to the container
+// or video it is about, like inReferenceField.
+func (record catalogRecord) attributeError(err error) error {
+	containerField, videoField := "containerID", "videoID"
+
+	switch {
+	case record.asset != nil:
+		if record.asset.ContainerExternalID != "" {
+			containerField = "containerExternalID"
+		}
+
+		if record.asset.VideoExternalID != "" {
+			videoField = "videoExternalID"
+		}
+	case record.video != nil && record.video.ContainerExternalID != "":
+		containerField = "containerExternalID"
+	}
+
+	return inReferenceField(err, []string{containerField}, []string{videoField}, nil)
+}
+
+// checkExternalIDs fail the records of an import that repeat the external ID of an earlier record of their type, and
+// those that refer by external ID to a record of the import that failed, which the store would not know about.
+// Containers are checked before the videos that refer to them, and videos before assets.
+func checkExternalIDs(rows []importer.Row, records []catalogRecord, errs []error) {
+	first := map[importKey]int{}
+	failed := map[importKey]bool{}
+
+	for _, nodeType := range []string{containerNode, videoNode, assetNode} {
+		for i, record := range records {
+			if record.nodeType != nodeType {
+				continue
+			}
+
+			if errs[i] == nil {
+				for _, reference := range record.references() {
+					if failed[reference] {
+						field := lowerFirst(reference.nodeType)
+						err := fmt.Errorf("%s %q: %w", field, reference.externalID, data.ErrOwnerNotCreated)
+						errs[i] = errors.Join(errs[i], inField(err, field+"ExternalID"))
+					}
+				}
+			}
+
+			if record.externalID == nil {
+				continue
+			}
+
+			key := importKey{externalID: *record.externalID, nodeType: nodeType}
+			if j, ok := first[key]; ok {
+				line := rows[j].Line
+				err := apperr.Errorf(apperr.Validation, "line %d already has external ID %q", line, key.externalID)
+				errs[i] = errors.Join(errs[i], inField(err, "externalID"))
+
+				continue
+			}
+
+			first[key] = i
+			failed[key] = errs[i] != nil
+		}
+	}
+}
+
+// decodeAsset decode the fields of an asset record of an import file.
+func (r *Resolver) decodeAsset(fields map[string]json.RawMessage) (catalogRecord, error) {
+	var input model.NewAsset
+	var containerRef containerReference
+	var videoRef videoReference
+
+	record := catalogRecord{nodeType: assetNode}
+
+	err := errors.Join(decodeFields(fields, &input, &containerRef, &videoRef), requireFields(fields, "assetType"))
+	if record.externalID = input.ExternalID; err != nil {
+		return record, err
+	}
+
+	containerID, containerExternalID, containerErr := r.importReference(
+		containerNode,
+		input.ContainerID,
+		containerRef.ContainerExternalID,
+		false,
+	)
+	videoID, videoExternalID, videoErr := r.importReference(videoNode, input.VideoID, videoRef.VideoExternalID, true)
+
+	record.asset = &data.CatalogAsset{
+		Asset: data.Asset{
+			AssetType:   data.AssetType(input.AssetType),
+			ContainerID: containerID,
+			ExternalID:  input.ExternalID,
+			Name:        input.Name,
+			URL:         input.URL,
+			VideoID:     videoID,
+		},
+		ContainerExternalID: containerExternalID,
+		VideoExternalID:     videoExternalID,
+	}
+
+	return record, errors.Join(validateInput(input), containerErr, videoErr)
+}
+
+// decodeContainer decode the fields of a container record of an import file.
+func decodeContainer(fields map[string]json.RawMessage) (catalogRecord, error) {
+	var input model.NewContainer
+
+	record := catalogRecord{nodeType: containerNode}
+
+	err := decodeFields(fields, &input)
+	if record.externalID = input.ExternalID; err != nil {
+		return record, err
+	}
+
+	record.container = &data.Container{
+		Description: input.Description,
+		ExternalID:  input.ExternalID,
+		Name:        input.Name,
+	}
+
+	return record, validateInput(input)
+}
+
+// decodeFields decode each of fields into the field of targets, pointers to structs, with its JSON name, and return
+// every problem, attributed to its field: fields that no target has, and values that do not fit theirs. JSON numbers
+// are accepted for string fields, such as IDs.
+func decodeFields(fields map[string]json.RawMessage, targets ...interface{}) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		field, ok := jsonField(name, targets)
+		if !ok {
+			errs = append(errs, inField(apperr.Errorf(apperr.Validation, "unknown field %s", name), name))
+
+			continue
+		}
+
+		raw := fields[name]
+
+		kind := field.Kind()
+		if kind == reflect.Pointer {
+			kind = field.Type().Elem().Kind()
+		}
+
+		var number json.Number
+		if kind == reflect.String && json.Unmarshal(raw, &number) == nil {
+			raw, _ = json.Marshal(number.String())
+		}
+
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			errs = append(errs, inField(apperr.Errorf(apperr.Validation, "invalid %s: %v", name, err), name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// decodeRecord decode the fields of a record of an import file according to its type.
+func (r *Resolver) decodeRecord(fields map[string]json.RawMessage) (catalogRecord, error) {
+	var nodeType string
+
+	err := json.Unmarshal(fields[typeField], &nodeType)
+	if err != nil || (nodeType != assetNode && nodeType != containerNode && nodeType != videoNode) {
+		return catalogRecord{}, inField(
+			apperr.Errorf(apperr.Validation, "type must be %s, %s, or %s", containerNode, videoNode, assetNode),
+			typeField,
+		)
+	}
+
+	fields = maps.Clone(fields)
+	delete(fields, typeField)
+
+	switch nodeType {
+	case assetNode:
+		return r.decodeAsset(fields)
+	case containerNode:
+		return decodeContainer(fields)
+	default:
+		return r.decodeVideo(fields)
+	}
+}
+
+// decodeVideo decode the fields of a video record of an import file.
+func (r *Resolver) decodeVideo(fields map[string]json.RawMessage) (catalogRecord, error) {
+	var input model.NewVideo
+	var containerRef containerReference
+
+	record := catalogRecord{nodeType: videoNode}
+
+	err := errors.Join(
+		decodeFields(fields, &input, &containerRef),
+		requireFields(fields, "videoType"),
+	)
+	if record.externalID = input.ExternalID; err != nil {
+		return record, err
+	}
+
+	containerID, containerExternalID, containerErr := r.importReference(
+		containerNode,
+		input.ContainerID,
+		containerRef.ContainerExternalID,
+		false,
+	)
+
+	record.video = &data.CatalogVideo{
+		Video: data.Video{
+			ContainerID:    containerID,
+			Description:    input.Description,
+			ExpirationDate: input.ExpirationDate,
+			ExternalID:     input.ExternalID,
+			PlaybackURL:    input.PlaybackURL,
+			Title:          input.Title,
+			VideoType:      data.VideoType(input.VideoType),
+		},
+		ContainerExternalID: containerExternalID,
+	}
+
+	return record, errors.Join(validateInput(input), containerErr)
+}
+
+// importReference primary key or external ID of the nodeType object that an import record refers to by ID or by
+// external ID, in the fields named after nodeType, of which it must give one unless optional. Empty IDs and "0" stand
+// for none.
+func (r *Resolver) importReference(
+	nodeType string,
+	id string,
+	externalID *string,
+	optional bool,
+) (uint, string, error) {
+	idField, externalIDField := lowerFirst(nodeType)+"ID", lowerFirst(nodeType)+"ExternalID"
+	hasID, hasExternalID := id != "" && id != "0", externalID != nil && *externalID != ""
+
+	switch {
+	case hasID && hasExternalID:
+		return 0, "", inField(
+			apperr.Errorf(apperr.Validation, "give %s or %s, not both", idField, externalIDField),
+			idField,
+		)
+	case hasExternalID:
+		return 0, *externalID, nil
+	case hasID:
+		key, err := r.primaryKey(nodeType, id)
+
+		return key, "", inField(err, idField)
+	case optional:
+		return 0, "", nil
+	default:
+		return 0, "", inField(
+			apperr.Errorf(apperr.Validation, "%s or %s is required", idField, externalIDField),
+			idField,
+		)
+	}
+}
+
+// isError whether err is not nil.
+func isError(err error) bool {
+	return err != nil
+}
+
+// jsonField settable field of targets, pointers to structs, with the JSON name name.
+func jsonField(name string, targets []interface{}) (reflect.Value, bool) {
+	for _, target := range targets {
+		value := reflect.ValueOf(target).Elem()
+
+		for i := range value.NumField() {
+			if tag, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ","); tag == name {
+				return value.Field(i), true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// lowerFirst text with its first letter in lower case, e.g. the name of the fields referring to a node type.
+func lowerFirst(text string) string {
+	return strings.ToLower(text[:1]) + text[1:]
+}
+
+// references external IDs of the records that the record refers to.
+func (record catalogRecord) references() []importKey {
+	var keys []importKey
+
+	switch {
+	case record.asset != nil:
+		keys = append(
+			keys,
+			importKey{externalID: record.asset.ContainerExternalID, nodeType: containerNode},
+			importKey{externalID: record.asset.VideoExternalID, nodeType: videoNode},
+		)
+	case record.video != nil:
+		keys = append(keys, importKey{externalID: record.video.ContainerExternalID, nodeType: containerNode})
+	}
+
+	return slices.DeleteFunc(keys, func(key importKey) bool { return key.externalID == "" })
+}
+
+// requireFields error for each of names that fields lacks or has set to null, attributed to it.
+func requireFields(fields map[string]json.RawMessage, names ...string) error {
+	var errs []error
+
+	for _, name := range names {
+		if raw, ok := fields[name]; !ok || string(raw) == "null" {
+			errs = append(errs, inField(apperr.Errorf(apperr.Validation, "%s is required", name), name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// toCatalog catalog of the records of an import without errors, and the indexes of the records of each of its lists.
+func toCatalog(records []catalogRecord, errs []error) (data.Catalog, catalogIndexes) {
+	var catalog data.Catalog
+	var indexes catalogIndexes
+
+	for i, record := range records {
+		switch {
+		case errs[i] != nil:
+		case record.asset != nil:
+			catalog.Assets = append(catalog.Assets, *record.asset)
+			indexes.assets = append(indexes.assets, i)
+		case record.container != nil:
+			catalog.Containers = append(catalog.Containers, *record.container)
+			indexes.containers = append(indexes.containers, i)
+		case record.video != nil:
+			catalog.Videos = append(catalog.Videos, *record.video)
+			indexes.videos = append(indexes.videos, i)
+		}
+	}
+
+	return catalog, indexes
+}
+
+// toImportReport report of an import, given each record's error, and otherwise its result and ID.
+func toImportReport(
+	rows []importer.Row,
+	records []catalogRecord,
+	errs []error,
+	results []data.ImportResult,
+	ids []uint,
+	options data.ImportOptions,
+) (*model.ImportReport, error) {
+	report := model.ImportReport{
+		Lines:      make([]*model.ImportLine, len(rows)),
+		RolledBack: options.DryRun || (!options.ContinueOnError && slices.ContainsFunc(errs, isError)),
+	}
+
+	for i, record := range records {
+		line := model.ImportLine{Errors: []*model.UserError{}, ExternalID: record.externalID, Line: int32(rows[i].Line)}
+		if record.nodeType != "" {
+			line.Type = &record.nodeType
+		}
+
+		switch {
+		case errs[i] != nil:
+			userErrors, err := toUserErrors(errs[i])
+			if err != nil {
+				return nil, err
+			}
+
+			line.Errors = userErrors
+			report.Failed++
+		case results[i].Updated:
+			action := model.ImportActionUpdate
+			line.Action = &action
+			report.Updated++
+		default:
+			action := model.ImportActionCreate
+			line.Action = &action
+			report.Created++
+		}
+
+		if errs[i] == nil && !report.RolledBack {
+			id := encodeID(record.nodeType, ids[i])
+			line.ID = &id
+		}
+
+		report.Lines[i] = &line
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/dataloader"
+	"context"
+	"net/http"
+	"time"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Loaders batching loaders for one request, so that resolving a field on many objects costs one query.
+type Loaders struct {
+	// AssetByID assets by ID.
+	AssetByID *dataloader.Loader[uint, data.Asset]
+	// AssetsByVideo assets of each video, by video ID.
+	AssetsByVideo *dataloader.Loader[uint, []data.Asset]
+	// ContainerByID containers, along with their unexpired videos and assets, by ID.
+	ContainerByID *dataloader.Loader[uint, data.Container]
+	// VideoByID videos by ID.
+	VideoByID *dataloader.Loader[uint, data.Video]
+}
+
+// loadersKey context key of the request's Loaders.
+type loadersKey struct{}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// LoaderMiddleware give each request handled by next its own Loaders.
+func LoaderMiddleware(store data.Store, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := context.WithValue(request.Context(), loadersKey{}, NewLoaders(store))
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// NewLoaders create Loaders reading from store.
+func NewLoaders(store data.Store) *Loaders {
+	return &Loaders{
+		AssetByID:     dataloader.New(store.GetAssetsByIDs, dataloader.DefaultWait, data.MaxPageSize),
+		AssetsByVideo: dataloader.New(store.GetAssetsByVideos, dataloader.DefaultWait, data.MaxPageSize),
+		ContainerByID: dataloader.New(store.GetContainersByIDs, dataloader.DefaultWait, data.MaxPageSize),
+		VideoByID:     dataloader.New(store.GetVideosByIDs, dataloader.DefaultWait, data.MaxPageSize),
+	}
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// loaders the request's Loaders, or Loaders for this call alone if LoaderMiddleware did not run.
+func (r *Resolver) loaders(ctx context.Context) *Loaders {
+	if loaders, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return loaders
+	}
+
+	return NewLoaders(r.Store)
+}
+
+// loadAsset asset matching assetID, or nil if there is none or, unless includeExpired, its video has expired.
+func (r *Resolver) loadAsset(ctx context.Context, assetID uint, includeExpired bool) (*model.Asset, error) {
+	asset, err := r.loaders(ctx).AssetByID.Load(ctx, assetID)
+	if err != nil || asset.ID == 0 {
+		return nil, err
+	}
+
+	if !includeExpired && asset.VideoID != 0 {
+		video, err := r.loaders(ctx).VideoByID.Load(ctx, asset.VideoID)
+		if err != nil {
+			return nil, err
+		}
+
+		if video.Expired(time.Now()) {
+			return nil, nil
+		}
+	}
+
+	return toModelAsset(asset), nil
+}
+
+// loadContainer container matching containerID, or nil if there is none.
+func (r *Resolver) loadContainer(ctx context.Context, containerID uint) (*model.Container, error) {
+	container, err := r.loaders(ctx).ContainerByID.Load(ctx, containerID)
+	if err != nil || container.ID == 0 {
+		return nil, err
+	}
+
+	return toModelContainer(container), nil
+}
+
+// loadNode object matching the global ID id, including expired videos and assets, or nil if there is none.
+func (r *Resolver) loadNode(ctx context.Context, id string) (model.Node, error) {
+	nodeType, key, err := decodeID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each case returns an untyped nil when there is no object, rather than a nil pointer in a non-nil interface.
+	switch nodeType {
+	case assetNode:
+		if asset, err := r.loadAsset(ctx, key, true); asset != nil || err != nil {
+			return asset, err
+		}
+	case containerNode:
+		if container, err := r.loadContainer(ctx, key); container != nil || err != nil {
+			return container, err
+		}
+	case videoNode:
+		if video, err := r.loadVideo(ctx, key, true); video != nil || err != nil {
+			return video, err
+		}
+	}
+
+	return nil, nil
+}
+
+// loadVideo video matching videoID, or nil if there is none or, unless includeExpired, it has expired.
+func (r *Resolver) loadVideo(ctx context.Context, videoID uint, includeExpired bool) (*model.Video, error) {
+	video, err := r.loaders(ctx).VideoByID.Load(ctx, videoID)
+	if err != nil || video.ID == 0 || (!includeExpired && video.Expired(time.Now())) {
+		return nil, err
+	}
+
+	return toModelVideo(video), nil
+}
+
+// loadVideoAssets assets of the video matching videoID, only those of assetType unless it is empty.
+func (r *Resolver) loadVideoAssets(
+	ctx context.Context,
+	videoID uint,
+	assetType data.AssetType,
+) ([]*model.Asset, error) {
+	assets, err := r.loaders(ctx).AssetsByVideo.Load(ctx, videoID)
+	if err != nil {
+		return []*model.Asset{}, err
+	}
+
+	result := make([]*model.Asset, 0, len(assets))
+
+	for _, asset := range assets {
+		if assetType == "" || asset.AssetType == assetType {
+			result = append(result, toModelAsset(asset))
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"github.com/99designs/gqlgen/graphql"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MarshalDateTime marshal t as an RFC 3339 DateTime in UTC.
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(t.UTC().Format(time.RFC3339Nano)))
+	})
+}
+
+// UnmarshalDateTime unmarshal an RFC 3339 DateTime, normalized to UTC.
+func UnmarshalDateTime(v any) (time.Time, error) {
+	text, ok := v.(string)
+	if !ok {
+		return time.Time{}, apperr.Errorf(apperr.Validation, "DateTime must be an RFC 3339 string, got %T", v)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return time.Time{}, apperr.Errorf(
+			apperr.Validation,
+			"DateTime must be an RFC 3339 date-time such as 2024-01-31T23:59:59Z, got %q",
+			text,
+		)
+	}
+
+	return t.UTC(), nil
+}
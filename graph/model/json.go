@@ -0,0 +1,28 @@
+package model
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"encoding/json"
+	"github.com/99designs/gqlgen/graphql"
+	"io"
+)
+
+// JSON raw, valid JSON value.
+type JSON []byte
+
+// MarshalJSON marshal raw as it is.
+func MarshalJSON(raw JSON) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = w.Write(raw)
+	})
+}
+
+// UnmarshalJSON unmarshal any JSON value.
+func UnmarshalJSON(v any) (JSON, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.Validation, "JSON must be a JSON value, got %T", v)
+	}
+
+	return raw, nil
+}
@@ -7,21 +7,236 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
+// Object that can be refetched by its ID.
+type Node interface {
+	IsNode()
+	GetID() string
+}
+
+type SearchNode interface {
+	IsSearchNode()
+}
+
 type Asset struct {
 	AssetType AssetType `json:"assetType"`
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	URL       string    `json:"url"`
+	// When the asset was deleted, or null if it has not been.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// ID of the asset in the system it was imported from, or null if it has none.
+	ExternalID *string `json:"externalID,omitempty"`
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	// Starts at 1 and is incremented by every change; pass it back to update or patch the asset.
+	Version int32 `json:"version"`
+	// ID of the container the asset belongs to.
+	ContainerID uint `json:"-"`
+	// ID of the video the asset belongs to, or 0 if it belongs to none.
+	VideoID uint `json:"-"`
+}
+
+func (Asset) IsNode()            {}
+func (this Asset) GetID() string { return this.ID }
+
+func (Asset) IsSearchNode() {}
+
+type AssetConnection struct {
+	Edges      []*AssetEdge `json:"edges"`
+	PageInfo   *PageInfo    `json:"pageInfo"`
+	TotalCount int32        `json:"totalCount"`
+}
+
+type AssetEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Asset `json:"node"`
+}
+
+type AssetFilter struct {
+	AssetType     *AssetType `json:"assetType,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Case-insensitive substring of the name.
+	Name          *string    `json:"name,omitempty"`
+	UpdatedAfter  *time.Time `json:"updatedAfter,omitempty"`
+	UpdatedBefore *time.Time `json:"updatedBefore,omitempty"`
+}
+
+// Sort order; results with equal fields are sorted by ID.
+type AssetOrder struct {
+	Direction *OrderDirection `json:"direction,omitempty"`
+	Field     AssetOrderField `json:"field"`
+}
+
+// Change to an asset, container, or video, recorded in the same transaction as the change.
+type AuditEvent struct {
+	// Who made the change: the authenticated caller of the request, anonymous if it had none, or a background job.
+	Actor string `json:"actor"`
+	// Snapshot of the stored object after the change, or null if it was purged.
+	After JSON `json:"after,omitempty"`
+	// Snapshot of the stored object before the change, or null if it was created.
+	Before JSON `json:"before,omitempty"`
+	// ID of the changed object, which may no longer exist.
+	EntityID string `json:"entityID"`
+	// Type of the changed object: Asset, Container, or Video.
+	EntityType string         `json:"entityType"`
+	OccurredAt time.Time      `json:"occurredAt"`
+	Operation  AuditOperation `json:"operation"`
+}
+
+type AuditEventConnection struct {
+	Edges      []*AuditEventEdge `json:"edges"`
+	PageInfo   *PageInfo         `json:"pageInfo"`
+	TotalCount int32             `json:"totalCount"`
+}
+
+type AuditEventEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *AuditEvent `json:"node"`
 }
 
 type Container struct {
-	Advertisements []*Asset `json:"advertisements"`
-	ID             uint     `json:"id"`
-	Images         []*Asset `json:"images"`
-	Name           string   `json:"name"`
-	Videos         []*Video `json:"videos"`
+	// When the container was deleted, or null if it has not been.
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	Description string     `json:"description"`
+	// ID of the container in the system it was imported from, or null if it has none.
+	ExternalID *string `json:"externalID,omitempty"`
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	// Starts at 1 and is incremented by every change; pass it back to update or patch the container.
+	Version int32 `json:"version"`
+	// Whether the videos and assets of the container include expired ones.
+	IncludeExpired bool `json:"-"`
+	// Primary key of the container, which ID encodes.
+	Key uint `json:"-"`
+}
+
+func (Container) IsNode()            {}
+func (this Container) GetID() string { return this.ID }
+
+type ContainerConnection struct {
+	Edges      []*ContainerEdge `json:"edges"`
+	PageInfo   *PageInfo        `json:"pageInfo"`
+	TotalCount int32            `json:"totalCount"`
+}
+
+type ContainerEdge struct {
+	Cursor string     `json:"cursor"`
+	Node   *Container `json:"node"`
+}
+
+type CreateAssetPayload struct {
+	Asset      *Asset       `json:"asset,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type CreateAssetsPayload struct {
+	// The assets in the order of the input, with null for those that were not created.
+	Assets []*Asset `json:"assets"`
+	// Problems with the assets, at paths such as ["input", "2", "url"].
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type CreateContainerPayload struct {
+	Container  *Container   `json:"container,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type CreateContainerWithContentPayload struct {
+	// The container's own assets in the order of the input, with null for those that were not created.
+	Assets    []*Asset   `json:"assets"`
+	Container *Container `json:"container,omitempty"`
+	// Problems with the container and its content, at paths such as ["input", "videos", "0", "title"].
+	UserErrors []*UserError `json:"userErrors"`
+	// The videos in the order of the input, with null for those that were not created.
+	Videos []*Video `json:"videos"`
+}
+
+type CreateVideoPayload struct {
+	UserErrors []*UserError `json:"userErrors"`
+	Video      *Video       `json:"video,omitempty"`
+}
+
+type CreateVideosPayload struct {
+	// Problems with the videos, at paths such as ["input", "2", "title"].
+	UserErrors []*UserError `json:"userErrors"`
+	// The videos in the order of the input, with null for those that were not created.
+	Videos []*Video `json:"videos"`
+}
+
+type DeleteAssetPayload struct {
+	DeletedAssetID *string      `json:"deletedAssetID,omitempty"`
+	UserErrors     []*UserError `json:"userErrors"`
+}
+
+type DeleteContainerPayload struct {
+	DeletedContainerID *string      `json:"deletedContainerID,omitempty"`
+	UserErrors         []*UserError `json:"userErrors"`
+}
+
+type DeleteVideoPayload struct {
+	DeletedVideoID *string      `json:"deletedVideoID,omitempty"`
+	UserErrors     []*UserError `json:"userErrors"`
+}
+
+type ExpirySweeperStats struct {
+	Action     ExpiryAction `json:"action"`
+	Error      *string      `json:"error,omitempty"`
+	Failed     int32        `json:"failed"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	// Whether this replica held the sweeper lock; other replicas skip the sweep.
+	Leader    bool      `json:"leader"`
+	Processed int32     `json:"processed"`
+	Scanned   int32     `json:"scanned"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+type ImportCatalogPayload struct {
+	Report *ImportReport `json:"report,omitempty"`
+	// Problems with the file as a whole, at ["file"] or ["format"].
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+// What importing one record of a file did.
+type ImportLine struct {
+	// What importing the record did, or null if it failed.
+	Action *ImportAction `json:"action,omitempty"`
+	// Problems with the record, at paths such as ["title"], or at null if no single field is at fault.
+	Errors     []*UserError `json:"errors"`
+	ExternalID *string      `json:"externalID,omitempty"`
+	// ID of the record, or null if it failed or the import was rolled back.
+	ID *string `json:"id,omitempty"`
+	// Line of the file that the record starts on, counting from 1.
+	Line int32 `json:"line"`
+	// Asset, Container, or Video, or null if the record has no valid type.
+	Type *string `json:"type,omitempty"`
+}
+
+// Report of an import, with a line for each record of the file, in order.
+type ImportReport struct {
+	// Number of records created, or that would have been had the import not been rolled back.
+	Created int32         `json:"created"`
+	Failed  int32         `json:"failed"`
+	Lines   []*ImportLine `json:"lines"`
+	// Whether every change was rolled back, because of a dry run or a failure without continueOnError.
+	RolledBack bool `json:"rolledBack"`
+	// Number of records updated, or that would have been had the import not been rolled back.
+	Updated int32 `json:"updated"`
+}
+
+// Live object whose reference to another object is broken.
+type IntegrityIssue struct {
+	ID      string           `json:"id"`
+	Problem IntegrityProblem `json:"problem"`
+	// ID of the referenced object, which may no longer exist.
+	ReferencedID string `json:"referencedID"`
+}
+
+// Live objects whose references are broken, ordered by ID.
+type IntegrityReport struct {
+	Assets []*IntegrityIssue `json:"assets"`
+	Videos []*IntegrityIssue `json:"videos"`
 }
 
 type Mutation struct {
@@ -29,51 +244,353 @@ type Mutation struct {
 
 type NewAsset struct {
 	AssetType   AssetType `json:"assetType"`
-	ContainerID uint      `json:"containerID"`
-	Name        string    `json:"name"`
-	URL         string    `json:"url"`
-	VideoID     uint      `json:"videoID"`
+	ContainerID string    `json:"containerID"`
+	// ID of the asset in the system it comes from, unique among assets.
+	ExternalID *string `json:"externalID,omitempty"`
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	// ID of the video the asset belongs to, which must be in the same container, or "0" for none.
+	VideoID string `json:"videoID"`
+}
+
+type NewContainer struct {
+	Description string `json:"description"`
+	// ID of the container in the system it comes from, unique among containers.
+	ExternalID *string `json:"externalID,omitempty"`
+	Name       string  `json:"name"`
+}
+
+// A container to create along with its videos and assets.
+type NewContainerWithContent struct {
+	// Assets of the container that belong to none of its videos.
+	Assets      []*NewContentAsset `json:"assets"`
+	Description string             `json:"description"`
+	Name        string             `json:"name"`
+	Videos      []*NewContentVideo `json:"videos"`
+}
+
+// An asset of a container being created, which it belongs to.
+type NewContentAsset struct {
+	AssetType AssetType `json:"assetType"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+}
+
+// A video of a container being created, which it belongs to.
+type NewContentVideo struct {
+	Assets      []*NewContentAsset `json:"assets"`
+	Description string             `json:"description"`
+	// When the video expires, or null if it never does.
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	PlaybackURL    string     `json:"playbackUrl"`
+	Title          string     `json:"title"`
+	VideoType      VideoType  `json:"videoType"`
 }
 
 type NewVideo struct {
-	ContainerID    uint      `json:"containerID"`
-	Description    string    `json:"description"`
-	ExpirationDate string    `json:"expirationDate"`
-	PlaybackURL    string    `json:"playbackUrl"`
-	Title          string    `json:"title"`
-	VideoType      VideoType `json:"videoType"`
+	ContainerID string `json:"containerID"`
+	Description string `json:"description"`
+	// When the video expires, or null if it never does.
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	// ID of the video in the system it comes from, unique among videos.
+	ExternalID  *string   `json:"externalID,omitempty"`
+	PlaybackURL string    `json:"playbackUrl"`
+	Title       string    `json:"title"`
+	VideoType   VideoType `json:"videoType"`
+}
+
+type PageInfo struct {
+	EndCursor       *string `json:"endCursor,omitempty"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+}
+
+type PatchAsset struct {
+	AssetType   *AssetType `json:"assetType,omitempty"`
+	ContainerID *string    `json:"containerID,omitempty"`
+	ID          string     `json:"id"`
+	Name        *string    `json:"name,omitempty"`
+	URL         *string    `json:"url,omitempty"`
+	// Version of the asset as last read; the patch fails with a CONFLICT user error if it has changed since.
+	Version int32 `json:"version"`
+	// ID of the video the asset belongs to, which must be in the same container, or "0" for none.
+	VideoID *string `json:"videoID,omitempty"`
+}
+
+type PatchAssetPayload struct {
+	Asset      *Asset       `json:"asset,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type PatchVideo struct {
+	// Remove the expiration date, so that the video never expires. Cannot be combined with expirationDate.
+	ClearExpirationDate *bool      `json:"clearExpirationDate,omitempty"`
+	ContainerID         *string    `json:"containerID,omitempty"`
+	Description         *string    `json:"description,omitempty"`
+	ExpirationDate      *time.Time `json:"expirationDate,omitempty"`
+	ID                  string     `json:"id"`
+	PlaybackURL         *string    `json:"playbackUrl,omitempty"`
+	Title               *string    `json:"title,omitempty"`
+	// Version of the video as last read; the patch fails with a CONFLICT user error if it has changed since.
+	Version   int32      `json:"version"`
+	VideoType *VideoType `json:"videoType,omitempty"`
+}
+
+type PatchVideoPayload struct {
+	UserErrors []*UserError `json:"userErrors"`
+	Video      *Video       `json:"video,omitempty"`
+}
+
+// Number of deleted objects of each type that a purge removed for good.
+type PurgePayload struct {
+	PurgedAssets     *int32       `json:"purgedAssets,omitempty"`
+	PurgedContainers *int32       `json:"purgedContainers,omitempty"`
+	PurgedVideos     *int32       `json:"purgedVideos,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
 }
 
 type Query struct {
 }
 
+type RestoreAssetPayload struct {
+	Asset      *Asset       `json:"asset,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type RestoreContainerPayload struct {
+	Container  *Container   `json:"container,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type RestoreVideoPayload struct {
+	Video      *Video       `json:"video,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type RevertVideoPayload struct {
+	Video      *Video       `json:"video,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type SearchResult struct {
+	Node SearchNode `json:"node"`
+	// Relevance; higher is better. Scores are only comparable within one search.
+	Score float64 `json:"score"`
+	// Excerpt of the matching text, with matching words between <b> and </b>. The text is HTML-escaped, so the markers
+	// are its only markup.
+	Snippet string `json:"snippet"`
+}
+
 type UpdateAsset struct {
 	AssetType   AssetType `json:"assetType"`
-	ContainerID uint      `json:"containerID"`
-	ID          uint      `json:"id"`
+	ContainerID string    `json:"containerID"`
+	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	URL         string    `json:"url"`
-	VideoID     uint      `json:"videoID"`
+	// Version of the asset as last read; the update fails with a CONFLICT user error if it has changed since.
+	Version int32 `json:"version"`
+	// ID of the video the asset belongs to, which must be in the same container, or "0" for none.
+	VideoID string `json:"videoID"`
+}
+
+type UpdateAssetPayload struct {
+	Asset      *Asset       `json:"asset,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
+}
+
+type UpdateContainer struct {
+	Description string `json:"description"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	// Version of the container as last read; the update fails with a CONFLICT user error if it has changed since.
+	Version int32 `json:"version"`
+}
+
+type UpdateContainerPayload struct {
+	Container  *Container   `json:"container,omitempty"`
+	UserErrors []*UserError `json:"userErrors"`
 }
 
 type UpdateVideo struct {
-	ContainerID    uint      `json:"containerID"`
-	Description    string    `json:"description"`
-	ExpirationDate string    `json:"expirationDate"`
-	ID             uint      `json:"id"`
-	PlaybackURL    string    `json:"playbackUrl"`
-	Title          string    `json:"title"`
-	VideoType      VideoType `json:"videoType"`
+	ContainerID string `json:"containerID"`
+	Description string `json:"description"`
+	// When the video expires, or null if it never does.
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	ID             string     `json:"id"`
+	PlaybackURL    string     `json:"playbackUrl"`
+	Title          string     `json:"title"`
+	// Version of the video as last read; the update fails with a CONFLICT user error if it has changed since.
+	Version   int32     `json:"version"`
+	VideoType VideoType `json:"videoType"`
+}
+
+type UpdateVideoPayload struct {
+	UserErrors []*UserError `json:"userErrors"`
+	Video      *Video       `json:"video,omitempty"`
+}
+
+// Problem with a mutation's input.
+type UserError struct {
+	Code UserErrorCode `json:"code"`
+	// Path to the input field at fault, e.g. ["input", "containerID"], or null if no single field is.
+	Field   []string `json:"field,omitempty"`
+	Message string   `json:"message"`
 }
 
 type Video struct {
-	Assets         []uint    `json:"assets"`
-	Description    string    `json:"description"`
-	ExpirationDate string    `json:"expirationDate"`
-	ID             uint      `json:"id"`
-	PlaybackURL    string    `json:"playbackUrl"`
-	Title          string    `json:"title"`
-	VideoType      VideoType `json:"videoType"`
+	// When the expiry sweeper archived the video, or null if it has not.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	// When the video was deleted, or null if it has not been.
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	Description string     `json:"description"`
+	// When the video expires, or null if it never does.
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	// ID of the video in the system it was imported from, or null if it has none.
+	ExternalID  *string `json:"externalID,omitempty"`
+	ID          string  `json:"id"`
+	PlaybackURL string  `json:"playbackUrl"`
+	Title       string  `json:"title"`
+	// Starts at 1 and is incremented by every change; pass it back to update or patch the video.
+	Version   int32     `json:"version"`
+	VideoType VideoType `json:"videoType"`
+	// ID of the container the video belongs to.
+	ContainerID uint `json:"-"`
+	// Primary key of the video, which ID encodes.
+	Key uint `json:"-"`
+}
+
+func (Video) IsNode()            {}
+func (this Video) GetID() string { return this.ID }
+
+func (Video) IsSearchNode() {}
+
+type VideoConnection struct {
+	Edges      []*VideoEdge `json:"edges"`
+	PageInfo   *PageInfo    `json:"pageInfo"`
+	TotalCount int32        `json:"totalCount"`
+}
+
+type VideoEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Video `json:"node"`
+}
+
+// Change to one field of a video between two revisions.
+type VideoFieldChange struct {
+	// Name of the field, as on VideoRevision.
+	Field string `json:"field"`
+	From  JSON   `json:"from,omitempty"`
+	To    JSON   `json:"to,omitempty"`
+}
+
+type VideoFilter struct {
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	ExpiresAfter  *time.Time `json:"expiresAfter,omitempty"`
+	ExpiresBefore *time.Time `json:"expiresBefore,omitempty"`
+	// Case-insensitive substring of the title.
+	Title         *string    `json:"title,omitempty"`
+	UpdatedAfter  *time.Time `json:"updatedAfter,omitempty"`
+	UpdatedBefore *time.Time `json:"updatedBefore,omitempty"`
+	VideoType     *VideoType `json:"videoType,omitempty"`
+}
+
+// Sort order; results with equal fields are sorted by ID.
+type VideoOrder struct {
+	Direction *OrderDirection `json:"direction,omitempty"`
+	Field     VideoOrderField `json:"field"`
+}
+
+// State of a video as of one of its numbered revisions, recorded with every change to it.
+type VideoRevision struct {
+	// Who made the change.
+	Actor       string     `json:"actor"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	ContainerID string     `json:"containerID"`
+	// When the change was made.
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeletedAt      *time.Time `json:"deletedAt,omitempty"`
+	Description    string     `json:"description"`
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	// What the change was.
+	Operation   AuditOperation `json:"operation"`
+	PlaybackURL string         `json:"playbackUrl"`
+	// Revision number, counting from 1.
+	Revision  int32     `json:"revision"`
+	Title     string    `json:"title"`
+	VideoType VideoType `json:"videoType"`
+}
+
+type VideoRevisionConnection struct {
+	Edges      []*VideoRevisionEdge `json:"edges"`
+	PageInfo   *PageInfo            `json:"pageInfo"`
+	TotalCount int32                `json:"totalCount"`
+}
+
+type VideoRevisionEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   *VideoRevision `json:"node"`
+}
+
+type AssetOrderField string
+
+const (
+	AssetOrderFieldCreatedAt AssetOrderField = "CREATED_AT"
+	AssetOrderFieldID        AssetOrderField = "ID"
+	// Ignoring case.
+	AssetOrderFieldName      AssetOrderField = "NAME"
+	AssetOrderFieldUpdatedAt AssetOrderField = "UPDATED_AT"
+)
+
+var AllAssetOrderField = []AssetOrderField{
+	AssetOrderFieldCreatedAt,
+	AssetOrderFieldID,
+	AssetOrderFieldName,
+	AssetOrderFieldUpdatedAt,
+}
+
+func (e AssetOrderField) IsValid() bool {
+	switch e {
+	case AssetOrderFieldCreatedAt, AssetOrderFieldID, AssetOrderFieldName, AssetOrderFieldUpdatedAt:
+		return true
+	}
+	return false
+}
+
+func (e AssetOrderField) String() string {
+	return string(e)
+}
+
+func (e *AssetOrderField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AssetOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AssetOrderField", str)
+	}
+	return nil
+}
+
+func (e AssetOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AssetOrderField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AssetOrderField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
 
 type AssetType string
@@ -131,6 +648,613 @@ func (e AssetType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type AuditOperation string
+
+const (
+	AuditOperationCreate AuditOperation = "CREATE"
+	AuditOperationDelete AuditOperation = "DELETE"
+	// The expiry sweeper processed the expired object.
+	AuditOperationExpire AuditOperation = "EXPIRE"
+	// The deleted object was deleted permanently.
+	AuditOperationPurge   AuditOperation = "PURGE"
+	AuditOperationRestore AuditOperation = "RESTORE"
+	// The video was reverted to one of its revisions.
+	AuditOperationRevert AuditOperation = "REVERT"
+	AuditOperationUpdate AuditOperation = "UPDATE"
+)
+
+var AllAuditOperation = []AuditOperation{
+	AuditOperationCreate,
+	AuditOperationDelete,
+	AuditOperationExpire,
+	AuditOperationPurge,
+	AuditOperationRestore,
+	AuditOperationRevert,
+	AuditOperationUpdate,
+}
+
+func (e AuditOperation) IsValid() bool {
+	switch e {
+	case AuditOperationCreate, AuditOperationDelete, AuditOperationExpire, AuditOperationPurge, AuditOperationRestore, AuditOperationRevert, AuditOperationUpdate:
+		return true
+	}
+	return false
+}
+
+func (e AuditOperation) String() string {
+	return string(e)
+}
+
+func (e *AuditOperation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditOperation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditOperation", str)
+	}
+	return nil
+}
+
+func (e AuditOperation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AuditOperation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AuditOperation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// What deleting a container does to its videos and assets.
+type ContainerDeletePolicy string
+
+const (
+	// Delete them along with the container.
+	ContainerDeletePolicyCascade ContainerDeletePolicy = "CASCADE"
+	// Refuse to delete the container while it has any.
+	ContainerDeletePolicyRestrict ContainerDeletePolicy = "RESTRICT"
+)
+
+var AllContainerDeletePolicy = []ContainerDeletePolicy{
+	ContainerDeletePolicyCascade,
+	ContainerDeletePolicyRestrict,
+}
+
+func (e ContainerDeletePolicy) IsValid() bool {
+	switch e {
+	case ContainerDeletePolicyCascade, ContainerDeletePolicyRestrict:
+		return true
+	}
+	return false
+}
+
+func (e ContainerDeletePolicy) String() string {
+	return string(e)
+}
+
+func (e *ContainerDeletePolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContainerDeletePolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContainerDeletePolicy", str)
+	}
+	return nil
+}
+
+func (e ContainerDeletePolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ContainerDeletePolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ContainerDeletePolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ExpiryAction string
+
+const (
+	ExpiryActionArchive ExpiryAction = "ARCHIVE"
+	ExpiryActionDelete  ExpiryAction = "DELETE"
+	ExpiryActionEvent   ExpiryAction = "EVENT"
+)
+
+var AllExpiryAction = []ExpiryAction{
+	ExpiryActionArchive,
+	ExpiryActionDelete,
+	ExpiryActionEvent,
+}
+
+func (e ExpiryAction) IsValid() bool {
+	switch e {
+	case ExpiryActionArchive, ExpiryActionDelete, ExpiryActionEvent:
+		return true
+	}
+	return false
+}
+
+func (e ExpiryAction) String() string {
+	return string(e)
+}
+
+func (e *ExpiryAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExpiryAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExpiryAction", str)
+	}
+	return nil
+}
+
+func (e ExpiryAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ExpiryAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ExpiryAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// What importing a record did, or would have done had the import not been rolled back.
+type ImportAction string
+
+const (
+	// The record was created.
+	ImportActionCreate ImportAction = "CREATE"
+	// The record updated the live record of its type with its external ID.
+	ImportActionUpdate ImportAction = "UPDATE"
+)
+
+var AllImportAction = []ImportAction{
+	ImportActionCreate,
+	ImportActionUpdate,
+}
+
+func (e ImportAction) IsValid() bool {
+	switch e {
+	case ImportActionCreate, ImportActionUpdate:
+		return true
+	}
+	return false
+}
+
+func (e ImportAction) String() string {
+	return string(e)
+}
+
+func (e *ImportAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportAction", str)
+	}
+	return nil
+}
+
+func (e ImportAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ImportAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ImportAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ImportFormat string
+
+const (
+	// Comma-separated values, with a header row naming the field of each column. Empty cells are left out.
+	ImportFormatCSV ImportFormat = "CSV"
+	// A JSON array of records.
+	ImportFormatJSON ImportFormat = "JSON"
+	// One JSON record per line.
+	ImportFormatNdjson ImportFormat = "NDJSON"
+)
+
+var AllImportFormat = []ImportFormat{
+	ImportFormatCSV,
+	ImportFormatJSON,
+	ImportFormatNdjson,
+}
+
+func (e ImportFormat) IsValid() bool {
+	switch e {
+	case ImportFormatCSV, ImportFormatJSON, ImportFormatNdjson:
+		return true
+	}
+	return false
+}
+
+func (e ImportFormat) String() string {
+	return string(e)
+}
+
+func (e *ImportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportFormat", str)
+	}
+	return nil
+}
+
+func (e ImportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ImportFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ImportFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// What is wrong with a reference from one object to another.
+type IntegrityProblem string
+
+const (
+	IntegrityProblemContainerDeleted IntegrityProblem = "CONTAINER_DELETED"
+	IntegrityProblemContainerMissing IntegrityProblem = "CONTAINER_MISSING"
+	// The referenced video belongs to another container than the referencing asset.
+	IntegrityProblemVideoContainerMismatch IntegrityProblem = "VIDEO_CONTAINER_MISMATCH"
+	IntegrityProblemVideoDeleted           IntegrityProblem = "VIDEO_DELETED"
+	IntegrityProblemVideoMissing           IntegrityProblem = "VIDEO_MISSING"
+)
+
+var AllIntegrityProblem = []IntegrityProblem{
+	IntegrityProblemContainerDeleted,
+	IntegrityProblemContainerMissing,
+	IntegrityProblemVideoContainerMismatch,
+	IntegrityProblemVideoDeleted,
+	IntegrityProblemVideoMissing,
+}
+
+func (e IntegrityProblem) IsValid() bool {
+	switch e {
+	case IntegrityProblemContainerDeleted, IntegrityProblemContainerMissing, IntegrityProblemVideoContainerMismatch, IntegrityProblemVideoDeleted, IntegrityProblemVideoMissing:
+		return true
+	}
+	return false
+}
+
+func (e IntegrityProblem) String() string {
+	return string(e)
+}
+
+func (e *IntegrityProblem) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = IntegrityProblem(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid IntegrityProblem", str)
+	}
+	return nil
+}
+
+func (e IntegrityProblem) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *IntegrityProblem) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e IntegrityProblem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderDirection string
+
+const (
+	OrderDirectionAsc  OrderDirection = "ASC"
+	OrderDirectionDesc OrderDirection = "DESC"
+)
+
+var AllOrderDirection = []OrderDirection{
+	OrderDirectionAsc,
+	OrderDirectionDesc,
+}
+
+func (e OrderDirection) IsValid() bool {
+	switch e {
+	case OrderDirectionAsc, OrderDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e OrderDirection) String() string {
+	return string(e)
+}
+
+func (e *OrderDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderDirection", str)
+	}
+	return nil
+}
+
+func (e OrderDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Why a mutation rejected its input.
+type UserErrorCode string
+
+const (
+	// The change conflicts with existing data.
+	UserErrorCodeConflict UserErrorCode = "CONFLICT"
+	// An ID is malformed or identifies an object of another type.
+	UserErrorCodeInvalidID UserErrorCode = "INVALID_ID"
+	// A value is not allowed.
+	UserErrorCodeInvalidValue UserErrorCode = "INVALID_VALUE"
+	// The object to change does not exist.
+	UserErrorCodeNotFound UserErrorCode = "NOT_FOUND"
+)
+
+var AllUserErrorCode = []UserErrorCode{
+	UserErrorCodeConflict,
+	UserErrorCodeInvalidID,
+	UserErrorCodeInvalidValue,
+	UserErrorCodeNotFound,
+}
+
+func (e UserErrorCode) IsValid() bool {
+	switch e {
+	case UserErrorCodeConflict, UserErrorCodeInvalidID, UserErrorCodeInvalidValue, UserErrorCodeNotFound:
+		return true
+	}
+	return false
+}
+
+func (e UserErrorCode) String() string {
+	return string(e)
+}
+
+func (e *UserErrorCode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserErrorCode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserErrorCode", str)
+	}
+	return nil
+}
+
+func (e UserErrorCode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserErrorCode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserErrorCode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// What deleting a video does to its assets.
+type VideoDeletePolicy string
+
+const (
+	// Delete them along with the video.
+	VideoDeletePolicyCascade VideoDeletePolicy = "CASCADE"
+	// Keep them in the container, belonging to no video.
+	VideoDeletePolicyDetach VideoDeletePolicy = "DETACH"
+	// Refuse to delete the video while it has any.
+	VideoDeletePolicyRestrict VideoDeletePolicy = "RESTRICT"
+)
+
+var AllVideoDeletePolicy = []VideoDeletePolicy{
+	VideoDeletePolicyCascade,
+	VideoDeletePolicyDetach,
+	VideoDeletePolicyRestrict,
+}
+
+func (e VideoDeletePolicy) IsValid() bool {
+	switch e {
+	case VideoDeletePolicyCascade, VideoDeletePolicyDetach, VideoDeletePolicyRestrict:
+		return true
+	}
+	return false
+}
+
+func (e VideoDeletePolicy) String() string {
+	return string(e)
+}
+
+func (e *VideoDeletePolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VideoDeletePolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VideoDeletePolicy", str)
+	}
+	return nil
+}
+
+func (e VideoDeletePolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VideoDeletePolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VideoDeletePolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type VideoOrderField string
+
+const (
+	VideoOrderFieldCreatedAt VideoOrderField = "CREATED_AT"
+	// Videos that never expire sort last.
+	VideoOrderFieldExpirationDate VideoOrderField = "EXPIRATION_DATE"
+	VideoOrderFieldID             VideoOrderField = "ID"
+	// Ignoring case.
+	VideoOrderFieldTitle     VideoOrderField = "TITLE"
+	VideoOrderFieldUpdatedAt VideoOrderField = "UPDATED_AT"
+)
+
+var AllVideoOrderField = []VideoOrderField{
+	VideoOrderFieldCreatedAt,
+	VideoOrderFieldExpirationDate,
+	VideoOrderFieldID,
+	VideoOrderFieldTitle,
+	VideoOrderFieldUpdatedAt,
+}
+
+func (e VideoOrderField) IsValid() bool {
+	switch e {
+	case VideoOrderFieldCreatedAt, VideoOrderFieldExpirationDate, VideoOrderFieldID, VideoOrderFieldTitle, VideoOrderFieldUpdatedAt:
+		return true
+	}
+	return false
+}
+
+func (e VideoOrderField) String() string {
+	return string(e)
+}
+
+func (e *VideoOrderField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VideoOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VideoOrderField", str)
+	}
+	return nil
+}
+
+func (e VideoOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VideoOrderField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VideoOrderField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type VideoType string
 
 const (
@@ -1,7 +1,22 @@
 package graph
 
+import (
+	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/expiry"
+)
+
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
-type Resolver struct{}
+type Resolver struct {
+	// AcceptNumericIDs accept bare primary keys, as well as global IDs, wherever an ID can only identify one type.
+	AcceptNumericIDs bool
+	// AdminToken bearer token that auditLog, expirySweeperStats, importCatalog, integrityReport, and purge require, or
+	// empty to disable them.
+	AdminToken string
+	// Store persistence layer for assets, containers, and videos.
+	Store data.Store
+	// Sweeper expiry sweeper, or nil if it is disabled.
+	Sweeper *expiry.Sweeper
+}
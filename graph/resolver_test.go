@@ -0,0 +1,355 @@
+package graph
+
+import (
+	"RocketContainer.go/internal/data"
+	"context"
+	"encoding/json"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.uber.org/zap"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	// testPurgeQuery query purging everything deleted.
+	testPurgeQuery = `mutation { purge(olderThanDays: 0) { purgedVideos } }`
+
+	// testActorHeader header naming the actor of requests to the test server.
+	testActorHeader = "X-Actor"
+
+	// testEditorToken bearer token of the editor actor of the test server.
+	testEditorToken = "editor-token"
+
+	// testToken admin token of the test server.
+	testToken = "test-token"
+
+	// testUserErrors selection of the user errors of a payload.
+	testUserErrors = `{ userErrors { code field } }`
+
+	// testVideoFields fields of a valid NewVideo, other than its container.
+	testVideoFields = `description: "", playbackUrl: "https://example.com/video", title: "video", videoType: CLIP`
+)
+
+/* ****************************************************************************************************************** *
+ *                                                       Tests                                                        *
+ * ****************************************************************************************************************** */
+
+func TestMutationPayloads(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		wantUserErrors []testUserError
+	}{
+		{
+			name:  "create video",
+			query: createVideoQuery(`"1"`),
+		},
+		{
+			name: "create video with blank title and bad URL",
+			query: `mutation { payload: createVideo(input: {containerID: "1", description: "", playbackUrl: "video", ` +
+				`title: " ", videoType: CLIP}) ` + testUserErrors + ` }`,
+			wantUserErrors: []testUserError{
+				{Code: "INVALID_VALUE", Field: []string{"input", "playbackUrl"}},
+				{Code: "INVALID_VALUE", Field: []string{"input", "title"}},
+			},
+		},
+		{
+			name:           "create video in missing container",
+			query:          createVideoQuery(`"9"`),
+			wantUserErrors: []testUserError{{Code: "INVALID_VALUE", Field: []string{"input", "containerID"}}},
+		},
+		{
+			name:           "create video in deleted container",
+			query:          createVideoQuery(`"2"`),
+			wantUserErrors: []testUserError{{Code: "CONFLICT", Field: []string{"input", "containerID"}}},
+		},
+		{
+			name:           "create video with ID of another type",
+			query:          createVideoQuery(`"` + encodeID(videoNode, 1) + `"`),
+			wantUserErrors: []testUserError{{Code: "INVALID_ID", Field: []string{"input", "containerID"}}},
+		},
+		{
+			name:           "delete missing video",
+			query:          `mutation { payload: deleteVideo(input: "9") ` + testUserErrors + ` }`,
+			wantUserErrors: []testUserError{{Code: "NOT_FOUND", Field: []string{"input"}}},
+		},
+		{
+			name: "patch video with both expiration date changes",
+			query: `mutation { payload: patchVideo(input: {id: "1", version: 1, clearExpirationDate: true, ` +
+				`expirationDate: "2030-01-01T00:00:00Z"}) ` + testUserErrors + ` }`,
+			wantUserErrors: []testUserError{{Code: "INVALID_VALUE", Field: []string{"input", "clearExpirationDate"}}},
+		},
+		{
+			name: "patch video at other version",
+			query: `mutation { payload: patchVideo(input: {id: "1", version: 9, title: "t"}) ` +
+				testUserErrors + ` }`,
+			wantUserErrors: []testUserError{{Code: "CONFLICT", Field: []string{"input", "version"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response struct {
+				Payload struct {
+					UserErrors []testUserError `json:"userErrors"`
+				} `json:"payload"`
+			}
+
+			if codes := postTestQuery(t, newTestClient(t), test.query, &response); len(codes) > 0 {
+				t.Fatalf("errors = %v, want none", codes)
+			}
+
+			if !reflect.DeepEqual(response.Payload.UserErrors, test.wantUserErrors) &&
+				len(response.Payload.UserErrors)+len(test.wantUserErrors) > 0 {
+				t.Errorf("userErrors = %v, want %v", response.Payload.UserErrors, test.wantUserErrors)
+			}
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		token     string
+		wantCodes []string
+	}{
+		{name: "video", query: `{ video(id: "1") { id } }`},
+		{name: "purge without token", query: testPurgeQuery, wantCodes: []string{"UNAUTHORIZED"}},
+		{
+			name:      "purge with wrong token",
+			query:     testPurgeQuery,
+			token:     "wrong",
+			wantCodes: []string{"UNAUTHORIZED"},
+		},
+		{name: "purge with token", query: testPurgeQuery, token: testToken},
+		{name: "audit log without token", query: `{ auditLog { totalCount } }`, wantCodes: []string{"UNAUTHORIZED"}},
+		{
+			name:  "audit log with token",
+			query: `{ auditLog(entityID: "1", entityType: "Video") { totalCount } }`,
+			token: testToken,
+		},
+		{
+			name:      "audit log with bare entity ID",
+			query:     `{ auditLog(entityID: "1") { totalCount } }`,
+			token:     testToken,
+			wantCodes: []string{"VALIDATION"},
+		},
+		{name: "malformed ID", query: `{ node(id: "x") { id } }`, wantCodes: []string{"VALIDATION"}},
+		{
+			name:      "negative expiresWithin",
+			query:     `{ videos(containerID: "1", expiresWithin: -1) { totalCount } }`,
+			wantCodes: []string{"VALIDATION"},
+		},
+		{
+			name:      "expiry sweeper stats without token",
+			query:     `{ expirySweeperStats { processed } }`,
+			wantCodes: []string{"UNAUTHORIZED"},
+		},
+		{name: "expiry sweeper stats with token", query: `{ expirySweeperStats { processed } }`, token: testToken},
+		{
+			name:      "too many nodes",
+			query:     `{ nodes(ids: [` + strings.Repeat(`"1", `, data.MaxPageSize+1) + `]) { id } }`,
+			wantCodes: []string{"VALIDATION"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var options []client.Option
+			if test.token != "" {
+				options = append(options, client.AddHeader("Authorization", bearerPrefix+test.token))
+			}
+
+			codes := postTestQuery(t, newTestClient(t), test.query, nil, options...)
+			if !reflect.DeepEqual(codes, test.wantCodes) && len(codes)+len(test.wantCodes) > 0 {
+				t.Errorf("error codes = %v, want %v", codes, test.wantCodes)
+			}
+		})
+	}
+}
+
+func TestActors(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		header    string
+		wantActor string
+	}{
+		{name: "actor token", token: testEditorToken, header: "spoofed", wantActor: "editor"},
+		{name: "admin token", token: testToken, wantActor: AdminActor},
+		{name: "trusted header", header: "proxy-user", wantActor: "proxy-user"},
+		{name: "unknown token", token: "wrong", wantActor: "anonymous"},
+		{name: "anonymous", wantActor: "anonymous"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var options []client.Option
+			if test.token != "" {
+				options = append(options, client.AddHeader("Authorization", bearerPrefix+test.token))
+			}
+
+			if test.header != "" {
+				options = append(options, client.AddHeader(testActorHeader, test.header))
+			}
+
+			c := newTestClient(t)
+			mutation := `mutation { payload: patchVideo(input: {id: "1", version: 1, title: "t"}) ` +
+				testUserErrors + ` }`
+			if codes := postTestQuery(t, c, mutation, nil, options...); len(codes) > 0 {
+				t.Fatalf("errors = %v, want none", codes)
+			}
+
+			var response struct {
+				AuditLog struct {
+					Edges []struct {
+						Node struct {
+							Actor string `json:"actor"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"auditLog"`
+			}
+
+			query := `{ auditLog(entityID: "1", entityType: "Video", last: 1) { edges { node { actor } } } }`
+			admin := client.AddHeader("Authorization", bearerPrefix+testToken)
+			if codes := postTestQuery(t, c, query, &response, admin); len(codes) > 0 {
+				t.Fatalf("errors = %v, want none", codes)
+			}
+
+			if edges := response.AuditLog.Edges; len(edges) != 1 || edges[0].Node.Actor != test.wantActor {
+				t.Errorf("edges = %v, want one by %q", edges, test.wantActor)
+			}
+		})
+	}
+}
+
+func TestContainerConnections(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		wantTotal int
+	}{
+		{name: "videos", field: "videos(first: 1)", wantTotal: 1},
+		{name: "advertisements", field: "advertisements", wantTotal: 0},
+		{name: "images", field: "images", wantTotal: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response struct {
+				Container struct {
+					Connection struct {
+						TotalCount int `json:"totalCount"`
+					} `json:"connection"`
+				} `json:"container"`
+			}
+
+			query := `{ container(containerID: "1") { connection: ` + test.field + ` { totalCount } } }`
+			if codes := postTestQuery(t, newTestClient(t), query, &response); len(codes) > 0 {
+				t.Fatalf("errors = %v, want none", codes)
+			}
+
+			if total := response.Container.Connection.TotalCount; total != test.wantTotal {
+				t.Errorf("totalCount = %d, want %d", total, test.wantTotal)
+			}
+		})
+	}
+}
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// testUserError user error as the tests select it.
+type testUserError struct {
+	Code  string   `json:"code"`
+	Field []string `json:"field"`
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// createVideoQuery query creating a valid video in the container of the given ID literal.
+func createVideoQuery(containerID string) string {
+	return `mutation { payload: createVideo(input: {` + testVideoFields + `, containerID: ` + containerID + `}) ` +
+		testUserErrors + ` }`
+}
+
+// newTestClient client of a server backed by a memory store holding a container with a video, and a deleted
+// container, with the middleware of the service.
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	ctx := context.Background()
+	store := data.NewMemoryStore(zap.NewNop())
+
+	for _, name := range []string{"container", "deleted"} {
+		container := data.Container{Name: name}
+		if err := store.CreateContainer(ctx, &container); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+
+	video := data.Video{ContainerID: 1, PlaybackURL: "https://example.com/video", Title: "video", VideoType: data.Clip}
+	if err := store.CreateVideo(ctx, &video); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+
+	if err := store.DeleteContainer(ctx, 2, data.Cascade); err != nil {
+		t.Fatalf("DeleteContainer() error = %v", err)
+	}
+
+	tokens := map[string]string{"editor": testEditorToken}
+	actors := Actors{AdminToken: testToken, Header: testActorHeader, Tokens: tokens}
+	resolver := Resolver{AcceptNumericIDs: true, AdminToken: testToken, Store: store}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &resolver}))
+	srv.SetErrorPresenter(NewErrorPresenter(zap.NewNop(), false))
+	srv.SetRecoverFunc(Recover)
+	srv.AddTransport(transport.POST{})
+
+	return client.New(ActorMiddleware(actors, TokenMiddleware(LoaderMiddleware(store, srv))))
+}
+
+// postTestQuery post query with options, decode its data into response unless it is nil, and return the extension
+// codes of its errors.
+func postTestQuery(
+	t *testing.T, c *client.Client, query string, response interface{}, options ...client.Option,
+) []string {
+	t.Helper()
+
+	raw, err := c.RawPost(query, options...)
+	if err != nil {
+		t.Fatalf("RawPost() error = %v", err)
+	}
+
+	if response != nil {
+		content, _ := json.Marshal(raw.Data)
+		if err := json.Unmarshal(content, response); err != nil {
+			t.Fatalf("decoding data: %v", err)
+		}
+	}
+
+	var errs []struct {
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	}
+
+	if len(raw.Errors) > 0 {
+		if err := json.Unmarshal(raw.Errors, &errs); err != nil {
+			t.Fatalf("decoding errors: %v", err)
+		}
+	}
+
+	var codes []string
+	for _, err := range errs {
+		codes = append(codes, err.Extensions.Code)
+	}
+
+	return codes
+}
@@ -6,10 +6,18 @@ package graph
 
 import (
 	"context"
-	"strconv"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
 	"RocketContainer.go/internal/data"
+	"RocketContainer.go/internal/importer"
+	"github.com/99designs/gqlgen/graphql"
+	"gorm.io/gorm"
 )
 
 /* ****************************************************************************************************************** *
@@ -17,45 +25,599 @@ import (
  * ****************************************************************************************************************** */
 
 // CreateAsset is the resolver for the createAsset field.
-func (r *mutationResolver) CreateAsset(ctx context.Context, input model.NewAsset) (uint, error) {
-	asset, err := data.CreateAsset(input)
+func (r *mutationResolver) CreateAsset(ctx context.Context, input model.NewAsset) (*model.CreateAssetPayload, error) {
+	asset, err := r.validateNewAsset(input, "input")
+	if err == nil {
+		err = inReferenceField(
+			r.Store.CreateAsset(ctx, &asset),
+			[]string{"input", "containerID"},
+			[]string{"input", "videoID"},
+			nil,
+		)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.CreateAssetPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.CreateAssetPayload{Asset: toModelAsset(asset)}, nil
+}
+
+// CreateAssets is the resolver for the createAssets field.
+func (r *mutationResolver) CreateAssets(
+	ctx context.Context,
+	input []*model.NewAsset,
+	continueOnError *bool,
+) (*model.CreateAssetsPayload, error) {
+	assets, err := createBatch(
+		input,
+		boolValue(continueOnError),
+		func(input *model.NewAsset, path ...string) (data.Asset, error) {
+			return r.validateNewAsset(*input, path...)
+		},
+		func(assets []data.Asset) ([]error, error) {
+			return r.Store.CreateAssets(ctx, assets, boolValue(continueOnError))
+		},
+	)
+
+	payload := model.CreateAssetsPayload{Assets: make([]*model.Asset, len(assets))}
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+		if err != nil {
+			return &model.CreateAssetsPayload{UserErrors: userErrors}, err
+		}
+
+		payload.UserErrors = userErrors
+	}
+
+	for i, asset := range assets {
+		if asset != nil {
+			payload.Assets[i] = toModelAsset(*asset)
+		}
+	}
+
+	return &payload, nil
+}
+
+// CreateContainer is the resolver for the createContainer field.
+func (r *mutationResolver) CreateContainer(
+	ctx context.Context,
+	input model.NewContainer,
+) (*model.CreateContainerPayload, error) {
+	container := data.Container{
+		Description: input.Description,
+		ExternalID:  input.ExternalID,
+		Name:        input.Name,
+	}
+
+	err := validateInput(input, "input")
+	if err == nil {
+		err = r.Store.CreateContainer(ctx, &container)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.CreateContainerPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.CreateContainerPayload{Container: toModelContainer(container)}, nil
+}
+
+// CreateContainerWithContent is the resolver for the createContainerWithContent field.
+func (r *mutationResolver) CreateContainerWithContent(
+	ctx context.Context,
+	input model.NewContainerWithContent,
+	continueOnError *bool,
+) (*model.CreateContainerWithContentPayload, error) {
+	payload := model.CreateContainerWithContentPayload{
+		Assets: make([]*model.Asset, len(input.Assets)),
+		Videos: make([]*model.Video, len(input.Videos)),
+	}
+
+	containerErr := validateInput(input, "input")
+	container, indexes, contentErr := toContainerWithContent(input, boolValue(continueOnError))
+
+	err := errors.Join(containerErr, contentErr)
+	if containerErr == nil && (contentErr == nil || boolValue(continueOnError)) {
+		errs, storeErr := r.Store.CreateContainerWithContent(ctx, &container, boolValue(continueOnError))
+		if storeErr != nil {
+			userErrors, err := toUserErrors(storeErr)
+
+			return &model.CreateContainerWithContentPayload{UserErrors: userErrors}, err
+		}
+
+		err = errors.Join(err, indexes.errors(errs))
+		if errs.Container == nil && (err == nil || boolValue(continueOnError)) {
+			created, err := r.Store.GetContainer(ctx, container.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			payload.Container = toModelContainer(created)
+
+			for k, i := range indexes.videos {
+				if errs.Videos[k] == nil {
+					payload.Videos[i] = toModelVideo(container.Videos[k])
+				}
+			}
 
-	return asset.ID, err
+			for k, i := range indexes.assets {
+				if errs.Assets[k] == nil {
+					payload.Assets[i] = toModelAsset(container.Assets[k])
+				}
+			}
+		}
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+		if err != nil {
+			return &model.CreateContainerWithContentPayload{UserErrors: userErrors}, err
+		}
+
+		payload.UserErrors = userErrors
+	}
+
+	return &payload, nil
 }
 
 // CreateVideo is the resolver for the createVideo field.
-func (r *mutationResolver) CreateVideo(ctx context.Context, input model.NewVideo) (uint, error) {
-	video, err := data.CreateVideo(input)
+func (r *mutationResolver) CreateVideo(ctx context.Context, input model.NewVideo) (*model.CreateVideoPayload, error) {
+	video, err := r.validateNewVideo(input, "input")
+	if err == nil {
+		err = inReferenceField(r.Store.CreateVideo(ctx, &video), []string{"input", "containerID"}, nil, nil)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
 
-	return video.ID, err
+		return &model.CreateVideoPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.CreateVideoPayload{Video: toModelVideo(video)}, nil
+}
+
+// CreateVideos is the resolver for the createVideos field.
+func (r *mutationResolver) CreateVideos(
+	ctx context.Context,
+	input []*model.NewVideo,
+	continueOnError *bool,
+) (*model.CreateVideosPayload, error) {
+	videos, err := createBatch(
+		input,
+		boolValue(continueOnError),
+		func(input *model.NewVideo, path ...string) (data.Video, error) {
+			return r.validateNewVideo(*input, path...)
+		},
+		func(videos []data.Video) ([]error, error) {
+			return r.Store.CreateVideos(ctx, videos, boolValue(continueOnError))
+		},
+	)
+
+	payload := model.CreateVideosPayload{Videos: make([]*model.Video, len(videos))}
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+		if err != nil {
+			return &model.CreateVideosPayload{UserErrors: userErrors}, err
+		}
+
+		payload.UserErrors = userErrors
+	}
+
+	for i, video := range videos {
+		if video != nil {
+			payload.Videos[i] = toModelVideo(*video)
+		}
+	}
+
+	return &payload, nil
 }
 
 // DeleteAsset is the resolver for the deleteAsset field.
-func (r *mutationResolver) DeleteAsset(ctx context.Context, input uint) (bool, error) {
-	err := data.DeleteAsset(input)
+func (r *mutationResolver) DeleteAsset(ctx context.Context, input string) (*model.DeleteAssetPayload, error) {
+	assetID, err := r.primaryKey(assetNode, input)
+	if err == nil {
+		err = r.Store.DeleteAsset(ctx, assetID)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.DeleteAssetPayload{UserErrors: userErrors}, err
+	}
+
+	deletedID := encodeID(assetNode, assetID)
+
+	return &model.DeleteAssetPayload{DeletedAssetID: &deletedID}, nil
+}
+
+// DeleteContainer is the resolver for the deleteContainer field.
+func (r *mutationResolver) DeleteContainer(ctx context.Context, input string, onContents *model.ContainerDeletePolicy) (*model.DeleteContainerPayload, error) {
+	containerID, err := r.primaryKey(containerNode, input)
+	if err == nil {
+		err = r.Store.DeleteContainer(ctx, containerID, toDeletePolicy(onContents))
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.DeleteContainerPayload{UserErrors: userErrors}, err
+	}
+
+	deletedID := encodeID(containerNode, containerID)
 
-	return err != nil, err
+	return &model.DeleteContainerPayload{DeletedContainerID: &deletedID}, nil
 }
 
 // DeleteVideo is the resolver for the deleteVideo field.
-func (r *mutationResolver) DeleteVideo(ctx context.Context, input uint) (bool, error) {
-	err := data.DeleteVideo(input)
+func (r *mutationResolver) DeleteVideo(ctx context.Context, input string, onAssets *model.VideoDeletePolicy) (*model.DeleteVideoPayload, error) {
+	videoID, err := r.primaryKey(videoNode, input)
+	if err == nil {
+		err = r.Store.DeleteVideo(ctx, videoID, toDeletePolicy(onAssets))
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.DeleteVideoPayload{UserErrors: userErrors}, err
+	}
+
+	deletedID := encodeID(videoNode, videoID)
+
+	return &model.DeleteVideoPayload{DeletedVideoID: &deletedID}, nil
+}
+
+// ImportCatalog is the resolver for the importCatalog field.
+func (r *mutationResolver) ImportCatalog(
+	ctx context.Context,
+	file graphql.Upload,
+	format *model.ImportFormat,
+	dryRun *bool,
+	continueOnError *bool,
+) (*model.ImportCatalogPayload, error) {
+	if err := r.authorizeAdmin(ctx, "importCatalog"); err != nil {
+		return nil, err
+	}
+
+	var importFormat importer.Format
+	var err error
+
+	if format != nil {
+		importFormat, err = importer.ParseFormat(string(*format))
+	} else {
+		importFormat, err = importer.FormatOf(file.Filename)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "format"))
+
+		return &model.ImportCatalogPayload{UserErrors: userErrors}, err
+	}
+
+	rows, err := importer.Read(file.File, importFormat)
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "file"))
+
+		return &model.ImportCatalogPayload{UserErrors: userErrors}, err
+	}
+
+	report, err := r.Import(
+		ctx,
+		rows,
+		data.ImportOptions{ContinueOnError: boolValue(continueOnError), DryRun: boolValue(dryRun)},
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	return err != nil, err
+	return &model.ImportCatalogPayload{Report: report}, nil
+}
+
+// PatchAsset is the resolver for the patchAsset field.
+func (r *mutationResolver) PatchAsset(ctx context.Context, input model.PatchAsset) (*model.PatchAssetPayload, error) {
+	patch := data.AssetPatch{Name: input.Name, URL: input.URL, Version: uint(input.Version)}
+
+	if input.AssetType != nil {
+		assetType := data.AssetType(*input.AssetType)
+		patch.AssetType = &assetType
+	}
+
+	assetID, err := r.primaryKey(assetNode, input.ID)
+	errs := []error{validateInput(input, "input"), inField(err, "input", "id")}
+
+	if input.ContainerID != nil {
+		containerID, err := r.primaryKey(containerNode, *input.ContainerID)
+		patch.ContainerID = &containerID
+		errs = append(errs, inField(err, "input", "containerID"))
+	}
+
+	if input.VideoID != nil {
+		videoID, err := r.optionalKey(videoNode, *input.VideoID)
+		patch.VideoID = &videoID
+		errs = append(errs, inField(err, "input", "videoID"))
+	}
+
+	// An asset moved to another container without its video is in a different container than the video.
+	videoField := []string{"input", "containerID"}
+	if patch.VideoID != nil {
+		videoField = []string{"input", "videoID"}
+	}
+
+	var asset data.Asset
+
+	err = errors.Join(errs...)
+	if err == nil {
+		asset, err = r.Store.PatchAsset(ctx, assetID, patch)
+		err = inReferenceField(err, []string{"input", "containerID"}, videoField, inUpdatedField)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.PatchAssetPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.PatchAssetPayload{Asset: toModelAsset(asset)}, nil
+}
+
+// PatchVideo is the resolver for the patchVideo field.
+func (r *mutationResolver) PatchVideo(ctx context.Context, input model.PatchVideo) (*model.PatchVideoPayload, error) {
+	patch := data.VideoPatch{
+		ClearExpirationDate: boolValue(input.ClearExpirationDate),
+		Description:         input.Description,
+		ExpirationDate:      input.ExpirationDate,
+		PlaybackURL:         input.PlaybackURL,
+		Title:               input.Title,
+		Version:             uint(input.Version),
+	}
+
+	if input.VideoType != nil {
+		videoType := data.VideoType(*input.VideoType)
+		patch.VideoType = &videoType
+	}
+
+	videoID, err := r.primaryKey(videoNode, input.ID)
+	errs := []error{validateInput(input, "input"), inField(err, "input", "id")}
+
+	if patch.ClearExpirationDate && patch.ExpirationDate != nil {
+		err := apperr.New(apperr.Validation, "clearExpirationDate cannot be combined with expirationDate")
+		errs = append(errs, inField(err, "input", "clearExpirationDate"))
+	}
+
+	if input.ContainerID != nil {
+		containerID, err := r.primaryKey(containerNode, *input.ContainerID)
+		patch.ContainerID = &containerID
+		errs = append(errs, inField(err, "input", "containerID"))
+	}
+
+	var video data.Video
+
+	err = errors.Join(errs...)
+	if err == nil {
+		video, err = r.Store.PatchVideo(ctx, videoID, patch)
+		err = inReferenceField(err, []string{"input", "containerID"}, nil, inUpdatedField)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.PatchVideoPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.PatchVideoPayload{Video: toModelVideo(video)}, nil
+}
+
+// Purge is the resolver for the purge field.
+func (r *mutationResolver) Purge(ctx context.Context, olderThanDays int32) (*model.PurgePayload, error) {
+	if err := r.authorizeAdmin(ctx, "purge"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if olderThanDays < 0 {
+		err = inField(apperr.New(apperr.Validation, "olderThanDays must not be negative"), "olderThanDays")
+	}
+
+	var result data.PurgeResult
+	if err == nil {
+		result, err = r.Store.Purge(ctx, time.Now().AddDate(0, 0, -int(olderThanDays)))
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.PurgePayload{UserErrors: userErrors}, err
+	}
+
+	assets, containers, videos := int32(result.Assets), int32(result.Containers), int32(result.Videos)
+
+	return &model.PurgePayload{PurgedAssets: &assets, PurgedContainers: &containers, PurgedVideos: &videos}, nil
+}
+
+// RestoreAsset is the resolver for the restoreAsset field.
+func (r *mutationResolver) RestoreAsset(ctx context.Context, input string) (*model.RestoreAssetPayload, error) {
+	var asset data.Asset
+
+	assetID, err := r.primaryKey(assetNode, input)
+	if err == nil {
+		asset, err = r.Store.RestoreAsset(ctx, assetID)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.RestoreAssetPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.RestoreAssetPayload{Asset: toModelAsset(asset)}, nil
+}
+
+// RestoreContainer is the resolver for the restoreContainer field.
+func (r *mutationResolver) RestoreContainer(ctx context.Context, input string, withContents *bool) (*model.RestoreContainerPayload, error) {
+	var container data.Container
+
+	containerID, err := r.primaryKey(containerNode, input)
+	if err == nil {
+		container, err = r.Store.RestoreContainer(ctx, containerID, boolValue(withContents))
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.RestoreContainerPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.RestoreContainerPayload{Container: toModelContainer(container)}, nil
+}
+
+// RestoreVideo is the resolver for the restoreVideo field.
+func (r *mutationResolver) RestoreVideo(ctx context.Context, input string, withAssets *bool) (*model.RestoreVideoPayload, error) {
+	var video data.Video
+
+	videoID, err := r.primaryKey(videoNode, input)
+	if err == nil {
+		video, err = r.Store.RestoreVideo(ctx, videoID, boolValue(withAssets))
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(inField(err, "input"))
+
+		return &model.RestoreVideoPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.RestoreVideoPayload{Video: toModelVideo(video)}, nil
+}
+
+// RevertVideo is the resolver for the revertVideo field.
+func (r *mutationResolver) RevertVideo(ctx context.Context, id string, revision int32) (*model.RevertVideoPayload, error) {
+	var video data.Video
+
+	videoID, err := r.primaryKey(videoNode, id)
+	if err != nil {
+		err = inField(err, "id")
+	} else if revision < 1 {
+		err = inField(apperr.New(apperr.Validation, "revision must be positive"), "revision")
+	} else {
+		video, err = r.Store.RevertVideo(ctx, videoID, uint(revision))
+		err = inField(err, "revision")
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.RevertVideoPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.RevertVideoPayload{Video: toModelVideo(video)}, nil
 }
 
 // UpdateAsset is the resolver for the updateAsset field.
-func (r *mutationResolver) UpdateAsset(ctx context.Context, input model.UpdateAsset) (bool, error) {
-	err := data.UpdateAsset(input)
+func (r *mutationResolver) UpdateAsset(ctx context.Context, input model.UpdateAsset) (*model.UpdateAssetPayload, error) {
+	assetID, idErr := r.primaryKey(assetNode, input.ID)
+	containerID, containerErr := r.primaryKey(containerNode, input.ContainerID)
+	videoID, videoErr := r.optionalKey(videoNode, input.VideoID)
+
+	asset := data.Asset{
+		Model:       gorm.Model{ID: assetID},
+		AssetType:   data.AssetType(input.AssetType),
+		ContainerID: containerID,
+		Name:        input.Name,
+		URL:         input.URL,
+		Version:     uint(input.Version),
+		VideoID:     videoID,
+	}
+
+	err := errors.Join(
+		validateInput(input, "input"),
+		inField(idErr, "input", "id"),
+		inField(containerErr, "input", "containerID"),
+		inField(videoErr, "input", "videoID"),
+	)
+	if err == nil {
+		err = inReferenceField(
+			r.Store.UpdateAsset(ctx, &asset),
+			[]string{"input", "containerID"},
+			[]string{"input", "videoID"},
+			inUpdatedField,
+		)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.UpdateAssetPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.UpdateAssetPayload{Asset: toModelAsset(asset)}, nil
+}
+
+// UpdateContainer is the resolver for the updateContainer field.
+func (r *mutationResolver) UpdateContainer(
+	ctx context.Context,
+	input model.UpdateContainer,
+) (*model.UpdateContainerPayload, error) {
+	containerID, err := r.primaryKey(containerNode, input.ID)
+
+	container := data.Container{
+		Model:       gorm.Model{ID: containerID},
+		Description: input.Description,
+		Name:        input.Name,
+		Version:     uint(input.Version),
+	}
+
+	err = errors.Join(validateInput(input, "input"), inField(err, "input", "id"))
+	if err == nil {
+		err = inUpdatedField(r.Store.UpdateContainer(ctx, &container))
+	}
 
-	return err != nil, err
+	if err == nil {
+		container, err = r.Store.GetContainer(ctx, containerID)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.UpdateContainerPayload{UserErrors: userErrors}, err
+	}
+
+	return &model.UpdateContainerPayload{Container: toModelContainer(container)}, nil
 }
 
 // UpdateVideo is the resolver for the updateVideo field.
-func (r *mutationResolver) UpdateVideo(ctx context.Context, input model.UpdateVideo) (bool, error) {
-	err := data.UpdateVideo(input)
+func (r *mutationResolver) UpdateVideo(ctx context.Context, input model.UpdateVideo) (*model.UpdateVideoPayload, error) {
+	videoID, idErr := r.primaryKey(videoNode, input.ID)
+	containerID, containerErr := r.primaryKey(containerNode, input.ContainerID)
+
+	video := data.Video{
+		Model:          gorm.Model{ID: videoID},
+		ContainerID:    containerID,
+		Description:    input.Description,
+		ExpirationDate: input.ExpirationDate,
+		PlaybackURL:    input.PlaybackURL,
+		Title:          input.Title,
+		Version:        uint(input.Version),
+		VideoType:      data.VideoType(input.VideoType),
+	}
+
+	err := errors.Join(
+		validateInput(input, "input"),
+		inField(idErr, "input", "id"),
+		inField(containerErr, "input", "containerID"),
+	)
+	if err == nil {
+		err = inReferenceField(r.Store.UpdateVideo(ctx, &video), []string{"input", "containerID"}, nil, inUpdatedField)
+	}
+
+	if err != nil {
+		userErrors, err := toUserErrors(err)
+
+		return &model.UpdateVideoPayload{UserErrors: userErrors}, err
+	}
 
-	return err != nil, err
+	return &model.UpdateVideoPayload{Video: toModelVideo(video)}, nil
 }
 
 /* ****************************************************************************************************************** *
@@ -63,272 +625,473 @@ func (r *mutationResolver) UpdateVideo(ctx context.Context, input model.UpdateVi
  * ****************************************************************************************************************** */
 
 // Advertisements is the resolver for the advertisements field.
-func (r *queryResolver) Advertisements(ctx context.Context, containerID uint) ([]*model.Asset, error) {
-	assets, err := data.GetAssets(containerID, data.Advertisement)
+func (r *queryResolver) Advertisements(ctx context.Context, containerID string, includeExpired *bool, filter *model.AssetFilter, orderBy *model.AssetOrder, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	key, err := r.primaryKey(containerNode, containerID)
+	if err != nil {
+		return &model.AssetConnection{}, err
+	}
 
+	assetFilter, err := toAssetFilterOfType(filter, includeExpired, data.Advertisement)
 	if err != nil {
-		return []*model.Asset{}, err
+		return &model.AssetConnection{}, err
 	}
 
-	results := make([]*model.Asset, 0, len(assets))
+	page := newPage(first, after, last, before)
+	assets, err := r.Store.GetAssets(ctx, key, assetFilter, toAssetOrder(orderBy), page)
 
-	for _, asset := range assets {
-		results = append(
-			results,
-			&model.Asset{
-				AssetType: model.AssetTypeAdvertisement,
-				ID:        asset.ID,
-				Name:      asset.Name,
-				URL:       asset.URL,
-			},
-		)
+	if err != nil {
+		return &model.AssetConnection{}, err
 	}
 
-	return results, nil
+	return toAssetConnection(assets), nil
 }
 
-// Container is the resolver for the container field.
-func (r *queryResolver) Container(ctx context.Context, containerID uint) (*model.Container, error) {
-	videos, err := data.GetVideosByContainer(containerID)
+// Asset is the resolver for the asset field.
+func (r *queryResolver) Asset(ctx context.Context, id string, includeExpired *bool) (*model.Asset, error) {
+	key, err := r.primaryKey(assetNode, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.loadAsset(ctx, key, boolValue(includeExpired))
+}
 
+// Assets is the resolver for the assets field.
+func (r *queryResolver) Assets(ctx context.Context, containerID string, includeExpired *bool, filter *model.AssetFilter, orderBy *model.AssetOrder, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	key, err := r.primaryKey(containerNode, containerID)
 	if err != nil {
-		return &model.Container{}, err
+		return &model.AssetConnection{}, err
 	}
 
-	advertisements := make([]*model.Asset, 0, 16)
-	images := make([]*model.Asset, 0, 16)
-	modelVideos := make([]*model.Video, 0, len(videos))
-
-	for _, video := range videos {
-		assets := make([]uint, 0, len(video.Assets))
-
-		for _, asset := range video.Assets {
-			if asset.AssetType == data.Advertisement {
-				advertisements = append(
-					advertisements,
-					&model.Asset{
-						AssetType: model.AssetTypeAdvertisement,
-						ID:        asset.ID,
-						Name:      asset.Name,
-						URL:       asset.URL,
-					},
-				)
-			} else {
-				images = append(
-					images,
-					&model.Asset{
-						AssetType: model.AssetTypeImage,
-						ID:        asset.ID,
-						Name:      asset.Name,
-						URL:       asset.URL,
-					},
-				)
-			}
+	page := newPage(first, after, last, before)
+	assets, err := r.Store.GetAssets(ctx, key, toAssetFilter(filter, includeExpired), toAssetOrder(orderBy), page)
+
+	if err != nil {
+		return &model.AssetConnection{}, err
+	}
+
+	return toAssetConnection(assets), nil
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, entityID *string, entityType *string, actor *string, since *time.Time, first *int32, after *string, last *int32, before *string) (*model.AuditEventConnection, error) {
+	if err := r.authorizeAdmin(ctx, "auditLog"); err != nil {
+		return nil, err
+	}
+
+	filter := data.AuditFilter{Actor: stringValue(actor), EntityType: stringValue(entityType), Since: since}
 
-			assets = append(
-				assets,
-				asset.ID,
-			)
+	if entityID != nil {
+		var err error
+
+		// Bare primary keys only identify an object along with its type.
+		if filter.EntityType != "" {
+			filter.EntityID, err = r.primaryKey(filter.EntityType, *entityID)
+		} else {
+			filter.EntityType, filter.EntityID, err = decodeID(*entityID)
 		}
 
-		modelVideos = append(
-			modelVideos,
-			&model.Video{
-				Assets:         assets,
-				Description:    video.Description,
-				ExpirationDate: video.ExpirationDate,
-				ID:             video.ID,
-				PlaybackURL:    video.PlaybackURL,
-				Title:          video.Title,
-				VideoType:      model.VideoType(video.VideoType),
-			},
-		)
+		if err != nil {
+			return &model.AuditEventConnection{}, err
+		}
 	}
 
-	result := model.Container{
-		Advertisements: advertisements,
-		ID:             containerID,
-		Images:         images,
-		Name:           getContainerName(containerID, advertisements, images),
-		Videos:         modelVideos,
+	events, err := r.Store.GetAuditEvents(ctx, filter, newPage(first, after, last, before))
+	if err != nil {
+		return &model.AuditEventConnection{}, err
 	}
 
-	return &result, nil
+	return toAuditEventConnection(events), nil
+}
+
+// Container is the resolver for the container field.
+func (r *queryResolver) Container(ctx context.Context, containerID string, includeExpired *bool) (*model.Container, error) {
+	key, err := r.primaryKey(containerNode, containerID)
+	if err != nil {
+		return &model.Container{}, err
+	}
+
+	container, err := r.Store.GetContainer(ctx, key)
+
+	if err != nil {
+		return &model.Container{}, err
+	}
+
+	result := toModelContainer(container)
+	result.IncludeExpired = boolValue(includeExpired)
+
+	return result, nil
 }
 
 // Containers is the resolver for the containers field.
-func (r *queryResolver) Containers(ctx context.Context) ([]*model.Container, error) {
-	videos, err := data.GetVideos()
-
-	if err != nil {
-		return []*model.Container{}, err
-	}
-
-	advertisementMap := make(map[uint][]*model.Asset, 16)
-	imageMap := make(map[uint][]*model.Asset, 16)
-	videoMap := make(map[uint][]*model.Video, 16)
-
-	for _, video := range videos {
-		advertisements := make([]*model.Asset, 0, 16)
-		assets := make([]uint, 0, len(video.Assets))
-		images := make([]*model.Asset, 0, 16)
-
-		for _, asset := range video.Assets {
-			if asset.AssetType == data.Advertisement {
-				advertisements = append(
-					advertisements,
-					&model.Asset{
-						AssetType: model.AssetTypeAdvertisement,
-						ID:        asset.ID,
-						Name:      asset.Name,
-						URL:       asset.URL,
-					},
-				)
-			} else {
-				images = append(
-					images,
-					&model.Asset{
-						AssetType: model.AssetTypeImage,
-						ID:        asset.ID,
-						Name:      asset.Name,
-						URL:       asset.URL,
-					},
-				)
-			}
+func (r *queryResolver) Containers(ctx context.Context, includeExpired *bool, first *int32, after *string, last *int32, before *string) (*model.ContainerConnection, error) {
+	containers, err := r.Store.GetContainers(ctx, newPage(first, after, last, before))
 
-			assets = append(
-				assets,
-				asset.ID,
-			)
+	if err != nil {
+		return &model.ContainerConnection{}, err
+	}
+
+	connection := toContainerConnection(containers)
+	for _, edge := range connection.Edges {
+		edge.Node.IncludeExpired = boolValue(includeExpired)
+	}
+
+	return connection, nil
+}
+
+// DeletedAssets is the resolver for the deletedAssets field.
+func (r *queryResolver) DeletedAssets(ctx context.Context, containerID *string, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	var key uint
+	if containerID != nil {
+		var err error
+		if key, err = r.primaryKey(containerNode, *containerID); err != nil {
+			return &model.AssetConnection{}, err
 		}
+	}
 
-		advertisementMap[video.ContainerID] = append(advertisementMap[video.ContainerID], advertisements...)
-		imageMap[video.ContainerID] = append(imageMap[video.ContainerID], images...)
-		videoMap[video.ContainerID] = append(
-			videoMap[video.ContainerID],
-			&model.Video{
-				Assets:         assets,
-				Description:    video.Description,
-				ExpirationDate: video.ExpirationDate,
-				ID:             video.ID,
-				PlaybackURL:    video.PlaybackURL,
-				Title:          video.Title,
-				VideoType:      model.VideoType(video.VideoType),
-			},
-		)
+	assets, err := r.Store.GetDeletedAssets(ctx, key, newPage(first, after, last, before))
+	if err != nil {
+		return &model.AssetConnection{}, err
+	}
+
+	return toAssetConnection(assets), nil
+}
+
+// DeletedContainers is the resolver for the deletedContainers field.
+func (r *queryResolver) DeletedContainers(ctx context.Context, first *int32, after *string, last *int32, before *string) (*model.ContainerConnection, error) {
+	containers, err := r.Store.GetDeletedContainers(ctx, newPage(first, after, last, before))
+	if err != nil {
+		return &model.ContainerConnection{}, err
 	}
 
-	results := make([]*model.Container, 0, len(videoMap))
+	return toContainerConnection(containers), nil
+}
 
-	for containerID, videos := range videoMap {
-		advertisements := advertisementMap[containerID]
-		images := imageMap[containerID]
+// DeletedVideos is the resolver for the deletedVideos field.
+func (r *queryResolver) DeletedVideos(ctx context.Context, containerID *string, first *int32, after *string, last *int32, before *string) (*model.VideoConnection, error) {
+	var key uint
+	if containerID != nil {
+		var err error
+		if key, err = r.primaryKey(containerNode, *containerID); err != nil {
+			return &model.VideoConnection{}, err
+		}
+	}
 
-		results = append(
-			results,
-			&model.Container{
-				Advertisements: advertisements,
-				ID:             containerID,
-				Images:         images,
-				Name:           getContainerName(containerID, advertisements, images),
-				Videos:         videos,
-			},
-		)
+	videos, err := r.Store.GetDeletedVideos(ctx, key, newPage(first, after, last, before))
+	if err != nil {
+		return &model.VideoConnection{}, err
+	}
+
+	return toVideoConnection(videos), nil
+}
+
+// ExpirySweeperStats is the resolver for the expirySweeperStats field.
+func (r *queryResolver) ExpirySweeperStats(ctx context.Context) (*model.ExpirySweeperStats, error) {
+	if err := r.authorizeAdmin(ctx, "expirySweeperStats"); err != nil {
+		return nil, err
+	}
+
+	if r.Sweeper == nil {
+		return nil, nil
 	}
 
-	return results, nil
+	stats := r.Sweeper.LastRun()
+	if stats == nil {
+		return nil, nil
+	}
+
+	result := model.ExpirySweeperStats{
+		Action:     model.ExpiryAction(strings.ToUpper(string(stats.Action))),
+		Failed:     int32(stats.Failed),
+		FinishedAt: stats.FinishedAt,
+		Leader:     stats.Leader,
+		Processed:  int32(stats.Processed),
+		Scanned:    int32(stats.Scanned),
+		StartedAt:  stats.StartedAt,
+	}
+
+	if stats.Error != nil {
+		message := stats.Error.Error()
+		result.Error = &message
+	}
+
+	return &result, nil
 }
 
 // Images is the resolver for the images field.
-func (r *queryResolver) Images(ctx context.Context, containerID uint) ([]*model.Asset, error) {
-	assets, err := data.GetAssets(containerID, data.Image)
+func (r *queryResolver) Images(ctx context.Context, containerID string, includeExpired *bool, filter *model.AssetFilter, orderBy *model.AssetOrder, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	key, err := r.primaryKey(containerNode, containerID)
+	if err != nil {
+		return &model.AssetConnection{}, err
+	}
 
+	assetFilter, err := toAssetFilterOfType(filter, includeExpired, data.Image)
 	if err != nil {
-		return []*model.Asset{}, err
+		return &model.AssetConnection{}, err
 	}
 
-	results := make([]*model.Asset, 0, len(assets))
+	page := newPage(first, after, last, before)
+	assets, err := r.Store.GetAssets(ctx, key, assetFilter, toAssetOrder(orderBy), page)
 
-	for _, asset := range assets {
-		results = append(
-			results,
-			&model.Asset{
-				AssetType: model.AssetTypeImage,
-				ID:        asset.ID,
-				Name:      asset.Name,
-				URL:       asset.URL,
-			},
-		)
+	if err != nil {
+		return &model.AssetConnection{}, err
 	}
 
-	return results, nil
+	return toAssetConnection(assets), nil
 }
 
-// Videos is the resolver for the videos field.
-func (r *queryResolver) Videos(ctx context.Context, containerID uint) ([]*model.Video, error) {
-	videos, err := data.GetVideosByContainer(containerID)
+// IntegrityReport is the resolver for the integrityReport field.
+func (r *queryResolver) IntegrityReport(ctx context.Context) (*model.IntegrityReport, error) {
+	if err := r.authorizeAdmin(ctx, "integrityReport"); err != nil {
+		return nil, err
+	}
 
+	report, err := r.Store.IntegrityReport(ctx)
 	if err != nil {
-		return []*model.Video{}, err
+		return nil, err
 	}
 
-	results := make([]*model.Video, 0, len(videos))
+	return toIntegrityReport(report), nil
+}
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
+	return r.loadNode(ctx, id)
+}
+
+// Nodes is the resolver for the nodes field.
+func (r *queryResolver) Nodes(ctx context.Context, ids []string) ([]model.Node, error) {
+	if len(ids) > data.MaxPageSize {
+		return nil, apperr.Errorf(apperr.Validation, "ids must have at most %d IDs", data.MaxPageSize)
+	}
+
+	nodes := make([]model.Node, len(ids))
+	errs := make([]error, len(ids))
+
+	// Loaded concurrently so that the loaders batch them.
+	var group sync.WaitGroup
 
-	for _, video := range videos {
-		assets := make([]uint, 0, len(video.Assets))
+	for i, id := range ids {
+		group.Add(1)
 
-		for _, asset := range video.Assets {
-			assets = append(
-				assets,
-				asset.ID,
-			)
+		go func() {
+			defer group.Done()
+			nodes[i], errs[i] = r.loadNode(ctx, id)
+		}()
+	}
+
+	group.Wait()
+
+	return nodes, errors.Join(errs...)
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, containerID *string, includeExpired *bool, first *int32) ([]*model.SearchResult, error) {
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+
+	if limit < 0 || limit > data.MaxPageSize {
+		err := apperr.Errorf(apperr.Validation, "first must be between 0 and %d", data.MaxPageSize)
+
+		return []*model.SearchResult{}, err
+	}
+
+	filter := data.SearchFilter{IncludeExpired: boolValue(includeExpired)}
+	if containerID != nil {
+		key, err := r.primaryKey(containerNode, *containerID)
+		if err != nil {
+			return []*model.SearchResult{}, err
 		}
 
-		results = append(
-			results,
-			&model.Video{
-				Assets:         assets,
-				Description:    video.Description,
-				ExpirationDate: video.ExpirationDate,
-				ID:             video.ID,
-				PlaybackURL:    video.PlaybackURL,
-				Title:          video.Title,
-				VideoType:      model.VideoType(video.VideoType),
-			},
-		)
+		filter.ContainerID = key
 	}
 
-	return results, nil
+	results, err := r.Store.Search(ctx, query, filter, limit)
+	if err != nil {
+		return []*model.SearchResult{}, err
+	}
+
+	searchResults := make([]*model.SearchResult, 0, len(results))
+
+	for _, result := range results {
+		searchResults = append(searchResults, toSearchResult(result))
+	}
+
+	return searchResults, nil
 }
 
-/* ****************************************************************************************************************** *
- *                                                     Resolvers                                                      *
- * ****************************************************************************************************************** */
+// Video is the resolver for the video field.
+func (r *queryResolver) Video(ctx context.Context, id string, includeExpired *bool) (*model.Video, error) {
+	key, err := r.primaryKey(videoNode, id)
+	if err != nil {
+		return nil, err
+	}
 
-// Mutation returns MutationResolver implementation.
-func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+	return r.loadVideo(ctx, key, boolValue(includeExpired))
+}
 
-// Query returns QueryResolver implementation.
-func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+// Videos is the resolver for the videos field.
+func (r *queryResolver) Videos(ctx context.Context, containerID string, includeExpired *bool, expiresWithin *int32, filter *model.VideoFilter, orderBy *model.VideoOrder, first *int32, after *string, last *int32, before *string) (*model.VideoConnection, error) {
+	key, err := r.primaryKey(containerNode, containerID)
+	if err != nil {
+		return &model.VideoConnection{}, err
+	}
 
-type mutationResolver struct{ *Resolver }
-type queryResolver struct{ *Resolver }
+	videoFilter := toVideoFilter(filter, includeExpired)
+
+	if expiresWithin != nil {
+		if *expiresWithin < 0 {
+			return &model.VideoConnection{}, apperr.New(apperr.Validation, "expiresWithin must not be negative")
+		}
+
+		expiresBefore := time.Now().Add(time.Duration(*expiresWithin) * time.Second)
+
+		if videoFilter.ExpiresBefore == nil || expiresBefore.Before(*videoFilter.ExpiresBefore) {
+			videoFilter.ExpiresBefore = &expiresBefore
+		}
+	}
+
+	page := newPage(first, after, last, before)
+	videos, err := r.Store.GetVideosByContainer(ctx, key, videoFilter, toVideoOrder(orderBy), page)
+
+	if err != nil {
+		return &model.VideoConnection{}, err
+	}
+
+	return toVideoConnection(videos), nil
+}
 
 /* ****************************************************************************************************************** *
- *                                                 Private functions                                                  *
+ *                                                       Fields                                                       *
  * ****************************************************************************************************************** */
 
-func getContainerName(containerID uint, advertisements []*model.Asset, images []*model.Asset) string {
-	adsName := ""
-	imagesName := ""
+// Container is the resolver for the container field.
+func (r *assetResolver) Container(ctx context.Context, obj *model.Asset) (*model.Container, error) {
+	return r.loadContainer(ctx, obj.ContainerID)
+}
+
+// Video is the resolver for the video field.
+func (r *assetResolver) Video(ctx context.Context, obj *model.Asset) (*model.Video, error) {
+	if obj.VideoID == 0 {
+		return nil, nil
+	}
+
+	return r.loadVideo(ctx, obj.VideoID, true)
+}
+
+// Advertisements is the resolver for the advertisements field.
+func (r *containerResolver) Advertisements(ctx context.Context, obj *model.Container, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	filter := data.AssetFilter{AssetType: data.Advertisement, IncludeExpired: obj.IncludeExpired}
+	assets, err := r.Store.GetAssets(ctx, obj.Key, filter, data.Order{}, newPage(first, after, last, before))
 
-	if len(advertisements) > 0 {
-		adsName = "_ads"
+	if err != nil {
+		return &model.AssetConnection{}, err
 	}
 
-	if len(images) > 0 {
-		imagesName = "_images"
+	return toAssetConnection(assets), nil
+}
+
+// Images is the resolver for the images field.
+func (r *containerResolver) Images(ctx context.Context, obj *model.Container, first *int32, after *string, last *int32, before *string) (*model.AssetConnection, error) {
+	filter := data.AssetFilter{AssetType: data.Image, IncludeExpired: obj.IncludeExpired}
+	assets, err := r.Store.GetAssets(ctx, obj.Key, filter, data.Order{}, newPage(first, after, last, before))
+
+	if err != nil {
+		return &model.AssetConnection{}, err
 	}
 
-	return "container-" + strconv.FormatUint(uint64(containerID), 10) + adsName + imagesName + "_videos"
+	return toAssetConnection(assets), nil
 }
+
+// Videos is the resolver for the videos field.
+func (r *containerResolver) Videos(ctx context.Context, obj *model.Container, first *int32, after *string, last *int32, before *string) (*model.VideoConnection, error) {
+	filter := data.VideoFilter{IncludeExpired: obj.IncludeExpired}
+	videos, err := r.Store.GetVideosByContainer(ctx, obj.Key, filter, data.Order{}, newPage(first, after, last, before))
+
+	if err != nil {
+		return &model.VideoConnection{}, err
+	}
+
+	return toVideoConnection(videos), nil
+}
+
+// Advertisements is the resolver for the advertisements field.
+func (r *videoResolver) Advertisements(ctx context.Context, obj *model.Video) ([]*model.Asset, error) {
+	return r.loadVideoAssets(ctx, obj.Key, data.Advertisement)
+}
+
+// Assets is the resolver for the assets field.
+func (r *videoResolver) Assets(ctx context.Context, obj *model.Video) ([]*model.Asset, error) {
+	return r.loadVideoAssets(ctx, obj.Key, "")
+}
+
+// Container is the resolver for the container field.
+func (r *videoResolver) Container(ctx context.Context, obj *model.Video) (*model.Container, error) {
+	container, err := r.loadContainer(ctx, obj.ContainerID)
+	if err == nil && container == nil {
+		err = fmt.Errorf("container %d: %w", obj.ContainerID, data.ErrNotFound)
+	}
+
+	return container, err
+}
+
+// Diff is the resolver for the diff field.
+func (r *videoResolver) Diff(ctx context.Context, obj *model.Video, from int32, to int32) ([]*model.VideoFieldChange, error) {
+	if from < 1 || to < 1 {
+		return nil, apperr.New(apperr.Validation, "from and to must be positive")
+	}
+
+	fromRevision, err := r.Store.GetVideoRevision(ctx, obj.Key, uint(from))
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", from, err)
+	}
+
+	toRevision, err := r.Store.GetVideoRevision(ctx, obj.Key, uint(to))
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", to, err)
+	}
+
+	return toVideoFieldChanges(fromRevision, toRevision), nil
+}
+
+// Images is the resolver for the images field.
+func (r *videoResolver) Images(ctx context.Context, obj *model.Video) ([]*model.Asset, error) {
+	return r.loadVideoAssets(ctx, obj.Key, data.Image)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *videoResolver) Revisions(ctx context.Context, obj *model.Video, first *int32, after *string, last *int32, before *string) (*model.VideoRevisionConnection, error) {
+	revisions, err := r.Store.GetVideoRevisions(ctx, obj.Key, newPage(first, after, last, before))
+	if err != nil {
+		return &model.VideoRevisionConnection{}, err
+	}
+
+	return toVideoRevisionConnection(revisions), nil
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Resolvers                                                      *
+ * ****************************************************************************************************************** */
+
+// Asset returns AssetResolver implementation.
+func (r *Resolver) Asset() AssetResolver { return &assetResolver{r} }
+
+// Container returns ContainerResolver implementation.
+func (r *Resolver) Container() ContainerResolver { return &containerResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Video returns VideoResolver implementation.
+func (r *Resolver) Video() VideoResolver { return &videoResolver{r} }
+
+type assetResolver struct{ *Resolver }
+type containerResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type videoResolver struct{ *Resolver }
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
+	"RocketContainer.go/internal/data"
+	"errors"
+	"github.com/vektah/gqlparser/v2/ast"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// checkConstraint error describing how text, the value of the input field name, violates constraint, if it does.
+func checkConstraint(constraint *ast.Directive, name string, text string) error {
+	if argument := constraint.Arguments.ForName("minLength"); argument != nil {
+		minLength, _ := strconv.Atoi(argument.Value.Raw)
+		if length := utf8.RuneCountInString(strings.TrimSpace(text)); length == 0 && minLength > 0 {
+			return apperr.Errorf(apperr.Validation, "%s must not be blank", name)
+		} else if length < minLength {
+			return apperr.Errorf(apperr.Validation, "%s must be at least %d characters long", name, minLength)
+		}
+	}
+
+	if argument := constraint.Arguments.ForName("maxLength"); argument != nil {
+		maxLength, _ := strconv.Atoi(argument.Value.Raw)
+		if utf8.RuneCountInString(text) > maxLength {
+			return apperr.Errorf(apperr.Validation, "%s must be at most %d characters long", name, maxLength)
+		}
+	}
+
+	if argument := constraint.Arguments.ForName("format"); argument != nil {
+		switch format := argument.Value.Raw; format {
+		case "url":
+			parsed, err := url.Parse(text)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return apperr.Errorf(apperr.Validation, "%s must be an absolute http or https URL", name)
+			}
+		default:
+			return apperr.Errorf(apperr.Internal, "unknown @constraint format %q", format)
+		}
+	}
+
+	return nil
+}
+
+// validateInput check input, a generated input struct, against the @constraint directives on the fields of the
+// GraphQL input type of the same name. Every violation is returned, joined, attributed to its field under path.
+func validateInput(input interface{}, path ...string) error {
+	value := reflect.Indirect(reflect.ValueOf(input))
+	definition := parsedSchema.Types[value.Type().Name()]
+
+	var errs []error
+
+	for i := range value.NumField() {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+
+		field := definition.Fields.ForName(name)
+		if field == nil || field.Directives.ForName("constraint") == nil {
+			continue
+		}
+
+		// Null values satisfy every constraint.
+		text := reflect.Indirect(value.Field(i))
+		if !text.IsValid() {
+			continue
+		}
+
+		err := checkConstraint(field.Directives.ForName("constraint"), name, text.String())
+		errs = append(errs, inField(err, slices.Concat(path, []string{name})...))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNewAsset database asset for input, and every problem with input, attributed to its field under path.
+func (r *Resolver) validateNewAsset(input model.NewAsset, path ...string) (data.Asset, error) {
+	containerID, containerErr := r.primaryKey(containerNode, input.ContainerID)
+	videoID, videoErr := r.optionalKey(videoNode, input.VideoID)
+
+	asset := data.Asset{
+		AssetType:   data.AssetType(input.AssetType),
+		ContainerID: containerID,
+		ExternalID:  input.ExternalID,
+		Name:        input.Name,
+		URL:         input.URL,
+		VideoID:     videoID,
+	}
+
+	return asset, errors.Join(
+		validateInput(input, path...),
+		inField(containerErr, slices.Concat(path, []string{"containerID"})...),
+		inField(videoErr, slices.Concat(path, []string{"videoID"})...),
+	)
+}
+
+// validateNewVideo database video for input, and every problem with input, attributed to its field under path.
+func (r *Resolver) validateNewVideo(input model.NewVideo, path ...string) (data.Video, error) {
+	containerID, err := r.primaryKey(containerNode, input.ContainerID)
+
+	video := data.Video{
+		ContainerID:    containerID,
+		Description:    input.Description,
+		ExpirationDate: input.ExpirationDate,
+		ExternalID:     input.ExternalID,
+		PlaybackURL:    input.PlaybackURL,
+		Title:          input.Title,
+		VideoType:      data.VideoType(input.VideoType),
+	}
+
+	return video, errors.Join(
+		validateInput(input, path...),
+		inField(err, slices.Concat(path, []string{"containerID"})...),
+	)
+}
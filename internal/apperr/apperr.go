@@ -0,0 +1,87 @@
+// Package apperr domain errors, classified by kind so that they can be reported without knowing where they came from.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Error domain error. Its message is safe to show clients; its cause, if any, is only for logs and is printed by the
+// %+v verb.
+type Error struct {
+	// Cause underlying error, or nil.
+	Cause error
+	// Kind class of the error.
+	Kind Kind
+	// Message description of the error.
+	Message string
+}
+
+// Kind class of a domain error, which is also its GraphQL extensions.code.
+type Kind string
+
+const (
+	// Conflict the change conflicts with existing data.
+	Conflict Kind = "CONFLICT"
+	// Internal failure that is not the client's fault.
+	Internal Kind = "INTERNAL_SERVER_ERROR"
+	// NotFound the object does not exist.
+	NotFound Kind = "NOT_FOUND"
+	// Unauthorized the client may not do this.
+	Unauthorized Kind = "UNAUTHORIZED"
+	// Validation the input is invalid.
+	Validation Kind = "VALIDATION"
+)
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// Errorf create an error of kind with a formatted message.
+func Errorf(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// KindOf kind of the first Error in err's tree, or Internal if there is none.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+
+	return Internal
+}
+
+// New create an error of kind.
+func New(kind Kind, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap create an error of kind caused by cause.
+func Wrap(kind Kind, cause error, message string) error {
+	return &Error{Cause: cause, Kind: kind, Message: message}
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Format print the message, followed by the cause for the %+v verb.
+func (err *Error) Format(state fmt.State, verb rune) {
+	if verb == 'v' && state.Flag('+') && err.Cause != nil {
+		_, _ = fmt.Fprintf(state, "%s: %+v", err.Message, err.Cause)
+
+		return
+	}
+
+	_, _ = io.WriteString(state, err.Message)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
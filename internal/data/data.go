@@ -2,37 +2,115 @@
 package data
 
 import (
-	"RocketContainer.go/graph/model"
+	"RocketContainer.go/internal/apperr"
+	"RocketContainer.go/internal/migrations"
+	"context"
 	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"moul.io/zapgorm2"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-var database *gorm.DB
-var logger *zap.Logger
+// AnonymousActor actor of the changes made with a context that names none.
+const AnonymousActor = "anonymous"
+
+var (
+	// ErrContainerNotEmpty returned when restricted from deleting a container that still has videos or assets.
+	ErrContainerNotEmpty = apperr.New(apperr.Conflict, "container still has videos or assets")
+	// ErrDeletedExternalID returned when importing a record whose external ID a deleted record has.
+	ErrDeletedExternalID = apperr.New(apperr.Conflict, "a deleted record has the external ID; restore or purge it")
+	// ErrDeletedOwner returned when writing or restoring a record that belongs to a deleted record.
+	ErrDeletedOwner = apperr.New(apperr.Conflict, "record belongs to a deleted record, which must be restored first")
+	// ErrExternalIDTaken returned when creating a record with the external ID of another record of its type.
+	ErrExternalIDTaken = apperr.New(apperr.Conflict, "another record already has the external ID")
+	// ErrMissingContainer returned when a video or asset references a container that does not exist.
+	ErrMissingContainer = apperr.New(apperr.Validation, "container does not exist")
+	// ErrMissingVideo returned when an asset references a video that does not exist.
+	ErrMissingVideo = apperr.New(apperr.Validation, "video does not exist")
+	// ErrNotFound returned when the requested record does not exist.
+	ErrNotFound = apperr.New(apperr.NotFound, "record not found")
+	// ErrOwnerNotCreated returned for a record of a batch that belongs to a record of the batch that failed.
+	ErrOwnerNotCreated = apperr.New(apperr.Conflict, "record belongs to a record that could not be created")
+	// ErrUnknownExternalID returned when importing a record that refers to another by an external ID that none has.
+	ErrUnknownExternalID = apperr.New(apperr.Validation, "no record has the external ID")
+	// ErrUnsupportedPolicy returned for delete policies that do not apply to the record being deleted.
+	ErrUnsupportedPolicy = apperr.New(apperr.Validation, "unsupported delete policy")
+	// ErrVideoContainerMismatch returned when an asset references a video in another container.
+	ErrVideoContainerMismatch = apperr.New(apperr.Validation, "video belongs to another container")
+	// ErrVersionConflict returned when updating a record that changed since the version the update was based on.
+	ErrVersionConflict = apperr.New(apperr.Conflict, "record changed since it was read; reload it and try again")
+	// ErrVideoHasAssets returned when restricted from deleting a video that still has assets.
+	ErrVideoHasAssets = apperr.New(apperr.Conflict, "video still has assets")
+)
 
 /* ****************************************************************************************************************** *
  *                                                  Type definitions                                                  *
  * ****************************************************************************************************************** */
 
+// actorKey context key of the actor responsible for the changes made with a context.
+type actorKey struct{}
+
 // Asset database type.
 type Asset struct {
 	gorm.Model
 	// AssetType asset type.
-	AssetType AssetType `gorm:"type:asset_type"`
+	AssetType AssetType
 	// ContainerID unique container ID.
 	ContainerID uint `gorm:"index"`
+	// ExternalID ID of the asset in the system it was imported from, unique among assets, or nil if it has none.
+	ExternalID *string
 	// Name asset name.
 	Name string
 	// URL asset URL.
 	URL string
-	// VideoID video ID foreign key.
+	// Version starts at 1 and is incremented by every change to the asset.
+	Version uint `gorm:"default:1"`
+	// VideoID video ID foreign key, or 0 if the asset belongs to no video. The video must be in the asset's container.
 	VideoID uint `gorm:"index"`
 }
 
+// AssetFilter criteria for asset queries. Zero-valued fields match every asset; time ranges are inclusive.
+type AssetFilter struct {
+	// AssetType only assets of this type.
+	AssetType AssetType
+	// CreatedAfter only assets created at or after this time.
+	CreatedAfter *time.Time
+	// CreatedBefore only assets created at or before this time.
+	CreatedBefore *time.Time
+	// IncludeExpired include assets of videos whose expiration date has passed.
+	IncludeExpired bool
+	// Name only assets whose name contains this, ignoring case.
+	Name string
+	// UpdatedAfter only assets last updated at or after this time.
+	UpdatedAfter *time.Time
+	// UpdatedBefore only assets last updated at or before this time.
+	UpdatedBefore *time.Time
+}
+
+// AssetPatch changes to an asset. Nil fields are left as they are.
+type AssetPatch struct {
+	// AssetType new asset type.
+	AssetType *AssetType
+	// ContainerID new container ID.
+	ContainerID *uint
+	// Name new asset name.
+	Name *string
+	// URL new asset URL.
+	URL *string
+	// Version version of the asset that the patch is based on.
+	Version uint
+	// VideoID new video ID, or 0 for none.
+	VideoID *uint
+}
+
 // AssetType asset reference type (ADVERTISEMENT or IMAGE).
 type AssetType string
 
@@ -43,22 +121,452 @@ const (
 	Image AssetType = "IMAGE"
 )
 
+// AuditEvent append-only record of a change to an asset, container, or video.
+type AuditEvent struct {
+	// ID event ID, increasing in the order events were recorded.
+	ID uint `gorm:"primaryKey"`
+	// Actor who made the change.
+	Actor string
+	// After JSON snapshot of the record after the change, or nil if it was purged.
+	After *string `gorm:"column:after_snapshot"`
+	// Before JSON snapshot of the record before the change, or nil if it was created.
+	Before *string `gorm:"column:before_snapshot"`
+	// EntityID ID of the record.
+	EntityID uint
+	// EntityType type of the record ("Asset", "Container", or "Video").
+	EntityType string
+	// OccurredAt when the change was made.
+	OccurredAt time.Time
+	// Operation what the change was.
+	Operation AuditOperation
+}
+
+// AuditFilter criteria for audit event queries. Zero-valued fields match every event.
+type AuditFilter struct {
+	// Actor only events of this actor.
+	Actor string
+	// EntityID only events of the record with this ID. Requires EntityType.
+	EntityID uint
+	// EntityType only events of records of this type.
+	EntityType string
+	// Since only events that occurred at or after this time.
+	Since *time.Time
+}
+
+// AuditOperation kind of change an audit event records.
+type AuditOperation string
+
+const (
+	// AuditCreate the record was created.
+	AuditCreate AuditOperation = "CREATE"
+	// AuditDelete the record was deleted.
+	AuditDelete AuditOperation = "DELETE"
+	// AuditExpire the expiry sweeper processed the expired record.
+	AuditExpire AuditOperation = "EXPIRE"
+	// AuditPurge the deleted record was deleted permanently.
+	AuditPurge AuditOperation = "PURGE"
+	// AuditRestore the deleted record was restored.
+	AuditRestore AuditOperation = "RESTORE"
+	// AuditRevert the video was reverted to one of its revisions.
+	AuditRevert AuditOperation = "REVERT"
+	// AuditUpdate the record was changed.
+	AuditUpdate AuditOperation = "UPDATE"
+)
+
+// batchStep run one creation of a batch, undoing it alone if it fails, and return its error.
+type batchStep func(create func() error) error
+
+// Catalog records to import, each created, or updated if a live record of its type has its external ID.
+type Catalog struct {
+	// Assets assets to import, after the videos.
+	Assets []CatalogAsset
+	// Containers containers to import, first.
+	Containers []Container
+	// Videos videos to import, after the containers.
+	Videos []CatalogVideo
+}
+
+// CatalogAsset asset to import, whose container and video external IDs, when set, take precedence over their IDs.
+type CatalogAsset struct {
+	Asset
+	// ContainerExternalID external ID of the container the asset belongs to, or empty.
+	ContainerExternalID string
+	// VideoExternalID external ID of the video the asset belongs to, or empty.
+	VideoExternalID string
+}
+
+// catalogOps how a store creates, finds, and updates records within the transaction importing a catalog.
+type catalogOps struct {
+	createAsset     func(asset *Asset) error
+	createContainer func(container *Container) error
+	createVideo     func(video *Video) error
+	// find the record of table, deleted or not, with the external ID. Returns ErrNotFound if there is none.
+	find            func(table string, externalID string) (externalRecord, error)
+	updateAsset     func(asset *Asset) error
+	updateContainer func(container *Container) error
+	updateVideo     func(video *Video) error
+}
+
+// catalogRefs IDs of the records of a catalog imported so far, and the records that failed, by external ID.
+type catalogRefs struct {
+	failed map[externalKey]bool
+	ids    map[externalKey]uint
+	ops    catalogOps
+}
+
+// CatalogResults results of importing each record of a catalog, indexed like it.
+type CatalogResults struct {
+	// Assets results of the assets.
+	Assets []ImportResult
+	// Containers results of the containers.
+	Containers []ImportResult
+	// Videos results of the videos.
+	Videos []ImportResult
+}
+
+// CatalogVideo video to import, whose ContainerExternalID, when set, takes precedence over its ContainerID.
+type CatalogVideo struct {
+	Video
+	// ContainerExternalID external ID of the container the video belongs to, or empty.
+	ContainerExternalID string
+}
+
+// Container database type.
+type Container struct {
+	gorm.Model
+	// Assets assets to create along with the container. Stores do not load them; use GetAssets.
+	Assets []Asset `json:"-"`
+	// Description container description.
+	Description string
+	// ExternalID ID of the container in the system it was imported from, unique among containers.
+	ExternalID *string
+	// Name container name.
+	Name string
+	// Version starts at 1 and is incremented by every change to the container.
+	Version uint `gorm:"default:1"`
+	// Videos videos to create along with the container. Stores do not load them; use GetVideosByContainer.
+	Videos []Video `json:"-"`
+}
+
+// ContentErrors errors of the content of a container created along with it, with nil for the records created.
+type ContentErrors struct {
+	// Assets errors of the container's own assets.
+	Assets []error
+	// Container error of the container itself. If it is not nil, none of the content was created.
+	Container error
+	// VideoAssets errors of the assets of each video.
+	VideoAssets [][]error
+	// Videos errors of the videos.
+	Videos []error
+}
+
+// DeletePolicy what deleting a record does to the live records that belong to it.
+type DeletePolicy string
+
+const (
+	// Cascade delete them along with it.
+	Cascade DeletePolicy = "CASCADE"
+	// Detach keep them, belonging to it no longer. Only videos can detach their assets.
+	Detach DeletePolicy = "DETACH"
+	// Restrict refuse to delete it while there are any.
+	Restrict DeletePolicy = "RESTRICT"
+)
+
+// ExpiryAction what to do with a video once its expiration date passes.
+type ExpiryAction string
+
+const (
+	// ExpiryArchive mark the video archived.
+	ExpiryArchive ExpiryAction = "archive"
+	// ExpiryDelete soft-delete the video.
+	ExpiryDelete ExpiryAction = "delete"
+	// ExpiryEvent leave the video as is; the caller only reports the expiry.
+	ExpiryEvent ExpiryAction = "event"
+)
+
+// externalKey external ID of a record of table.
+type externalKey struct {
+	externalID string
+	table      string
+}
+
+// externalRecord record found by its external ID.
+type externalRecord struct {
+	// DeletedAt when the record was deleted, if it was.
+	DeletedAt gorm.DeletedAt
+	// ID record ID.
+	ID uint
+	// Version record version.
+	Version uint
+}
+
+// ImportOptions how to import a catalog.
+type ImportOptions struct {
+	// ContinueOnError keep the records that were imported even if others failed, rather than rolling back every one.
+	ContinueOnError bool
+	// DryRun roll back every record once they have all been imported, reporting what importing them would do.
+	DryRun bool
+}
+
+// ImportResult result of importing one record of a catalog.
+type ImportResult struct {
+	// Err why the record could not be imported, or nil if it was.
+	Err error
+	// Updated whether the record updated the live record with its external ID rather than being created.
+	Updated bool
+}
+
+// IntegrityIssue live row whose reference to another row is broken.
+type IntegrityIssue struct {
+	// ID ID of the row.
+	ID uint
+	// Problem what is wrong with the reference.
+	Problem IntegrityProblem
+	// ReferencedID ID of the referenced row.
+	ReferencedID uint
+}
+
+// IntegrityProblem what is wrong with a reference.
+type IntegrityProblem string
+
+const (
+	// ContainerDeleted the referenced container has been deleted.
+	ContainerDeleted IntegrityProblem = "CONTAINER_DELETED"
+	// ContainerMissing the referenced container does not exist.
+	ContainerMissing IntegrityProblem = "CONTAINER_MISSING"
+	// VideoContainerMismatch the referenced video belongs to another container.
+	VideoContainerMismatch IntegrityProblem = "VIDEO_CONTAINER_MISMATCH"
+	// VideoDeleted the referenced video has been deleted.
+	VideoDeleted IntegrityProblem = "VIDEO_DELETED"
+	// VideoMissing the referenced video does not exist.
+	VideoMissing IntegrityProblem = "VIDEO_MISSING"
+)
+
+// IntegrityReport live rows whose references are broken, ordered by ID.
+type IntegrityReport struct {
+	// Assets assets whose container or video is missing or deleted, or whose video belongs to another container.
+	Assets []IntegrityIssue
+	// Videos videos whose container is missing or deleted.
+	Videos []IntegrityIssue
+}
+
+// localLocks process-local named locks, for stores that are not shared between processes.
+type localLocks struct {
+	held  map[int64]bool
+	mutex sync.Mutex
+}
+
+// PurgeResult number of records of each type that a purge deleted permanently.
+type PurgeResult struct {
+	// Assets number of assets deleted.
+	Assets int64
+	// Containers number of containers deleted.
+	Containers int64
+	// Videos number of videos deleted.
+	Videos int64
+}
+
+// Store persists assets, containers, and videos.
+type Store interface {
+	// CreateAsset create the asset, checking that its container and video are live and match.
+	CreateAsset(ctx context.Context, asset *Asset) error
+	// CreateAssets create the assets in one transaction, and return the error of each by index.
+	CreateAssets(ctx context.Context, assets []Asset, continueOnError bool) ([]error, error)
+	// DeleteAsset delete the asset matching assetID.
+	DeleteAsset(ctx context.Context, assetID uint) error
+	// GetAssets get a page of assets matching containerID and filter, sorted by order.
+	GetAssets(
+		ctx context.Context,
+		containerID uint,
+		filter AssetFilter,
+		order Order,
+		page Page,
+	) (Connection[Asset], error)
+	// GetAssetsByIDs get the assets matching assetIDs, by ID, whether or not they have expired.
+	GetAssetsByIDs(ctx context.Context, assetIDs []uint) (map[uint]Asset, error)
+	// GetAssetsByVideos get the assets of each video matching videoIDs, ordered by ID.
+	GetAssetsByVideos(ctx context.Context, videoIDs []uint) (map[uint][]Asset, error)
+	// GetDeletedAssets get a page of deleted assets matching containerID, or of every container if it is 0.
+	GetDeletedAssets(ctx context.Context, containerID uint, page Page) (Connection[Asset], error)
+	// PatchAsset apply patch to the asset matching assetID and return the result.
+	PatchAsset(ctx context.Context, assetID uint, patch AssetPatch) (Asset, error)
+	// RestoreAsset restore the deleted asset matching assetID.
+	RestoreAsset(ctx context.Context, assetID uint) (Asset, error)
+	// UpdateAsset update the asset, then reload it.
+	UpdateAsset(ctx context.Context, asset *Asset) error
+
+	// CreateContainer create the container.
+	CreateContainer(ctx context.Context, container *Container) error
+	// CreateContainerWithContent create the container along with its videos and assets, and return their errors.
+	CreateContainerWithContent(ctx context.Context, container *Container, continueOnError bool) (ContentErrors, error)
+	// DeleteContainer delete the container matching containerID, applying onContents to its videos and assets.
+	DeleteContainer(ctx context.Context, containerID uint, onContents DeletePolicy) error
+	// GetContainer get the container matching containerID, without its videos and assets.
+	GetContainer(ctx context.Context, containerID uint) (Container, error)
+	// GetContainers get a page of containers, without their videos and assets.
+	GetContainers(ctx context.Context, page Page) (Connection[Container], error)
+	// GetContainersByIDs get the containers matching containerIDs, by ID.
+	GetContainersByIDs(ctx context.Context, containerIDs []uint) (map[uint]Container, error)
+	// GetDeletedContainers get a page of deleted containers.
+	GetDeletedContainers(ctx context.Context, page Page) (Connection[Container], error)
+	// RestoreContainer restore the deleted container matching containerID, with its contents if withContents.
+	RestoreContainer(ctx context.Context, containerID uint, withContents bool) (Container, error)
+	// UpdateContainer update the container's name and description, then reload it.
+	UpdateContainer(ctx context.Context, container *Container) error
+
+	// CreateVideo create the video, checking that its container is live.
+	CreateVideo(ctx context.Context, video *Video) error
+	// CreateVideos create the videos in one transaction, returning their errors like CreateAssets.
+	CreateVideos(ctx context.Context, videos []Video, continueOnError bool) ([]error, error)
+	// DeleteVideo delete the video matching videoID, applying onAssets to its assets.
+	DeleteVideo(ctx context.Context, videoID uint, onAssets DeletePolicy) error
+	// GetDeletedVideos get a page of deleted videos matching containerID, or of every container if it is 0.
+	GetDeletedVideos(ctx context.Context, containerID uint, page Page) (Connection[Video], error)
+	// GetVideosByContainer get a page of videos matching containerID and filter, sorted by order.
+	GetVideosByContainer(
+		ctx context.Context,
+		containerID uint,
+		filter VideoFilter,
+		order Order,
+		page Page,
+	) (Connection[Video], error)
+	// GetVideosByIDs get the videos matching videoIDs, by ID, whether or not they have expired.
+	GetVideosByIDs(ctx context.Context, videoIDs []uint) (map[uint]Video, error)
+	// PatchVideo apply patch to the video matching videoID, moving its assets along, and return the result.
+	PatchVideo(ctx context.Context, videoID uint, patch VideoPatch) (Video, error)
+	// GetVideoRevision get revision number revision of the video matching videoID.
+	GetVideoRevision(ctx context.Context, videoID uint, revision uint) (VideoRevision, error)
+	// GetVideoRevisions get a page of the revisions of the video matching videoID, oldest first.
+	GetVideoRevisions(ctx context.Context, videoID uint, page Page) (Connection[VideoRevision], error)
+	// RestoreVideo restore the deleted video matching videoID, with the assets deleted with it if withAssets.
+	RestoreVideo(ctx context.Context, videoID uint, withAssets bool) (Video, error)
+	// RevertVideo set the video matching videoID back to its revision number revision, recording a new revision.
+	RevertVideo(ctx context.Context, videoID uint, revision uint) (Video, error)
+	// UpdateVideo update the video, moving its assets along, then reload it.
+	UpdateVideo(ctx context.Context, video *Video) error
+
+	// GetAuditEvents get a page of the audit events matching filter, oldest first.
+	GetAuditEvents(ctx context.Context, filter AuditFilter, page Page) (Connection[AuditEvent], error)
+
+	// ImportCatalog import the containers, videos, and assets of catalog, creating or updating records by external ID.
+	ImportCatalog(ctx context.Context, catalog *Catalog, options ImportOptions) (CatalogResults, error)
+
+	// Search get up to limit videos and assets matching every word of query, most relevant first.
+	Search(ctx context.Context, query string, filter SearchFilter, limit int) ([]SearchResult, error)
+
+	// IntegrityReport find the live rows whose references are broken, such as assets of deleted videos.
+	IntegrityReport(ctx context.Context) (IntegrityReport, error)
+
+	// Purge permanently delete the records deleted before deletedBefore that no remaining record references.
+	Purge(ctx context.Context, deletedBefore time.Time) (PurgeResult, error)
+
+	// ExpireVideo apply action to the expired video matching videoID and mark it processed as of now.
+	ExpireVideo(ctx context.Context, videoID uint, action ExpiryAction, now time.Time) error
+	// GetExpiredVideos get up to limit videos that expired as of now and have not been processed, ordered by ID.
+	GetExpiredVideos(ctx context.Context, now time.Time, limit int) ([]Video, error)
+	// TryLock try to take the lock matching key without waiting, and return a function releasing it if acquired.
+	TryLock(ctx context.Context, key int64) (unlock func(), acquired bool, err error)
+}
+
+// Video database type.
 type Video struct {
 	gorm.Model
-	// Assets that belong to the video.
-	Assets []Asset
+	// ArchivedAt when the video was archived after expiring, or nil if it has not been.
+	ArchivedAt *time.Time
+	// Assets that belong to the video. Stores do not load them; use GetAssetsByVideos.
+	Assets []Asset `json:"-"`
 	// ContainerID unique container ID.
 	ContainerID uint `gorm:"index"`
 	// Description video description.
 	Description string
-	// ExpirationDate expiration date.
-	ExpirationDate string
+	// ExpirationDate when the video expires, or nil if it never does.
+	ExpirationDate *time.Time `gorm:"index"`
+	// ExpiryProcessedAt when the expiry sweeper handled the expired video, or nil if it has not.
+	ExpiryProcessedAt *time.Time
+	// ExternalID ID of the video in the system it was imported from, unique among videos, or nil if it has none.
+	ExternalID *string
 	// PlaybackURL video playback URL.
 	PlaybackURL string
 	// Title video title.
 	Title string
+	// Version starts at 1 and is incremented by every change to the video.
+	Version uint `gorm:"default:1"`
 	// VideoType video type (CLIP, EPISODE, or MOVIE).
-	VideoType VideoType `gorm:"type:video_type"`
+	VideoType VideoType
+}
+
+// VideoFilter criteria for video queries. Zero-valued fields match every video; time ranges are inclusive.
+type VideoFilter struct {
+	// CreatedAfter only videos created at or after this time.
+	CreatedAfter *time.Time
+	// CreatedBefore only videos created at or before this time.
+	CreatedBefore *time.Time
+	// ExpiresAfter only videos that expire at or after this time.
+	ExpiresAfter *time.Time
+	// ExpiresBefore only videos that expire at or before this time.
+	ExpiresBefore *time.Time
+	// IncludeExpired include videos whose expiration date has passed.
+	IncludeExpired bool
+	// Title only videos whose title contains this, ignoring case.
+	Title string
+	// UpdatedAfter only videos last updated at or after this time.
+	UpdatedAfter *time.Time
+	// UpdatedBefore only videos last updated at or before this time.
+	UpdatedBefore *time.Time
+	// VideoType only videos of this type.
+	VideoType VideoType
+}
+
+// VideoPatch changes to a video. Nil fields are left as they are.
+type VideoPatch struct {
+	// ClearExpirationDate remove the expiration date, so that the video never expires.
+	ClearExpirationDate bool
+	// ContainerID new container ID.
+	ContainerID *uint
+	// Description new video description.
+	Description *string
+	// ExpirationDate new expiration date.
+	ExpirationDate *time.Time
+	// PlaybackURL new video playback URL.
+	PlaybackURL *string
+	// Title new video title.
+	Title *string
+	// Version version of the video that the patch is based on.
+	Version uint
+	// VideoType new video type.
+	VideoType *VideoType
+}
+
+// VideoRevision numbered state of a video, recorded along with the audit event of each change to it.
+type VideoRevision struct {
+	// ID revision ID, increasing in the order revisions were recorded.
+	ID uint `gorm:"primaryKey"`
+	// Actor who made the change.
+	Actor string
+	// ArchivedAt when the video had been archived, or nil.
+	ArchivedAt *time.Time
+	// ContainerID container the video belonged to.
+	ContainerID uint
+	// CreatedAt when the change was made.
+	CreatedAt time.Time
+	// DeletedAt when the video had been deleted, or nil.
+	DeletedAt *time.Time
+	// Description video description.
+	Description string
+	// ExpirationDate when the video expired, or nil if it never did.
+	ExpirationDate *time.Time
+	// Operation what the change was.
+	Operation AuditOperation
+	// PlaybackURL video playback URL.
+	PlaybackURL string
+	// Revision revision number, counting from 1 for each video.
+	Revision uint
+	// Title video title.
+	Title string
+	// VideoID ID of the video.
+	VideoID uint
+	// VideoType video type.
+	VideoType VideoType
 }
 
 // VideoType video type (CLIP, EPISODE, or MOVIE).
@@ -77,203 +585,628 @@ const (
  *                                                     Functions                                                      *
  * ****************************************************************************************************************** */
 
-// InitDb initialize database.
-func InitDb() {
-	logger = zap.L().Named("database")
-	gormLogger := zapgorm2.New(logger)
-	gormLogger.SetAsDefault()
-
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPass := os.Getenv("DB_PASSWORD")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
+// InitDb initialize the store selected by DB_DRIVER, applying any pending migrations.
+func InitDb() Store {
+	logger := zap.L().Named("database")
 
-	dsn := "host=" + dbHost + " user=" + dbUser + " password=" + dbPass + " dbname=" + dbName + " port=" + dbPort
+	if os.Getenv("DB_DRIVER") == "memory" {
+		return NewMemoryStore(logger)
+	}
 
-	db, dbErr := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
+	db, dbErr := OpenDb()
 	if dbErr != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(dbErr))
 	}
 
-	migrationErr := database.AutoMigrate(&Asset{}, &Video{})
-	if migrationErr != nil {
+	migrator, migratorErr := migrations.New(db, logger.Named("migrations"))
+	if migratorErr != nil {
+		logger.Fatal("Failed to load migrations", zap.Error(migratorErr))
+	}
+
+	if _, migrationErr := migrator.Up(context.Background()); migrationErr != nil {
 		logger.Fatal("Failed to migrate database", zap.Error(migrationErr))
 	}
 
-	database = db
+	return NewGormStore(db, logger)
 }
 
-/* ************************************************* Asset ************************************************** */
-
-// CreateAsset create the asset in the database.
-func CreateAsset(new model.NewAsset) (Asset, error) {
-	logger.Debug(
-		"Creating asset",
-		zap.String("assetType", string(new.AssetType)),
-		zap.Uint("containerID", new.ContainerID),
-		zap.String("name", new.Name),
-		zap.String("url", new.URL),
-		zap.Uint("videoID", new.VideoID),
-	)
+// OpenDb connect to the SQL database selected by DB_DRIVER without migrating it.
+func OpenDb() (*gorm.DB, error) {
+	logger := zap.L().Named("database")
 
-	asset := Asset{
-		AssetType:   AssetType(new.AssetType),
-		ContainerID: new.ContainerID,
-		Name:        new.Name,
-		URL:         new.URL,
-		VideoID:     new.VideoID,
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "postgres":
+		return openGorm(postgresDialector(), logger)
+	case "sqlite":
+		return openGorm(sqliteDialector(), logger)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
 	}
-	result := database.Create(&asset)
-
-	return asset, result.Error
 }
 
-// DeleteAsset delete the asset matching assetID from the database.
-func DeleteAsset(assetID uint) error {
-	logger.Debug("Deleting asset", zap.Uint("assetID", assetID))
-
-	return database.Delete(&Asset{}, assetID).Error
+// WithActor ctx naming actor as who makes the changes made with it, which audit events record.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
 }
 
-// GetAssets get all assets matching containerID and assetType.
-func GetAssets(containerID uint, assetType AssetType) ([]Asset, error) {
-	logger.Debug(
-		"Getting assets",
-		zap.Uint("containerID", containerID),
-		zap.String("assetType", string(assetType)),
-	)
-
-	var assets []Asset
-	result := database.Where("container_id = ? AND asset_type = ?", containerID, assetType).Find(&assets)
+/* ************************************************** Asset patch *************************************************** */
 
-	return assets, result.Error
+// apply set the fields of asset that the patch changes.
+func (patch AssetPatch) apply(asset *Asset) {
+	setIfPresent(&asset.AssetType, patch.AssetType)
+	setIfPresent(&asset.ContainerID, patch.ContainerID)
+	setIfPresent(&asset.Name, patch.Name)
+	setIfPresent(&asset.URL, patch.URL)
+	setIfPresent(&asset.VideoID, patch.VideoID)
 }
 
-// UpdateAsset update the asset in the database.
-func UpdateAsset(update model.UpdateAsset) error {
-	logger.Debug(
-		"Updating asset",
-		zap.String("assetType", string(update.AssetType)),
-		zap.Uint("containerID", update.ContainerID),
-		zap.Uint("id", update.ID),
-		zap.String("name", update.Name),
-		zap.String("url", update.URL),
-		zap.Uint("videoID", update.VideoID),
-	)
+// columns new values of the columns that the patch changes.
+func (patch AssetPatch) columns() map[string]interface{} {
+	columns := map[string]interface{}{}
+	addIfPresent(columns, "asset_type", patch.AssetType)
+	addIfPresent(columns, "container_id", patch.ContainerID)
+	addIfPresent(columns, "name", patch.Name)
+	addIfPresent(columns, "url", patch.URL)
 
-	asset := Asset{
-		Model:       gorm.Model{ID: update.ID},
-		AssetType:   AssetType(update.AssetType),
-		ContainerID: update.ContainerID,
-		Name:        update.Name,
-		URL:         update.URL,
-		VideoID:     update.VideoID,
+	if patch.VideoID != nil {
+		columns["video_id"] = nullableID(*patch.VideoID)
 	}
 
-	return database.Save(&asset).Error
+	return columns
 }
 
 /* *************************************************** Asset type *************************************************** */
 
 func (assetType *AssetType) Scan(value interface{}) error {
-	*assetType = AssetType(value.([]byte))
+	text, err := scanText(value)
+	*assetType = AssetType(text)
 
-	return nil
+	return err
 }
 
 func (assetType AssetType) Value() (driver.Value, error) {
 	return string(assetType), nil
 }
 
-/* ***************************************************** Video ****************************************************** */
+/* ************************************************** Catalog refs ************************************************** */
+
+// add note the ID of the record of table with externalID, or that it failed to import.
+func (refs catalogRefs) add(table string, externalID *string, id uint, err error) {
+	if externalID == nil {
+		return
+	}
+
+	key := externalKey{externalID: *externalID, table: table}
+	if err != nil {
+		refs.failed[key] = true
+	} else {
+		refs.ids[key] = id
+	}
+}
 
-// CreateVideo create the video in the database.
-func CreateVideo(new model.NewVideo) (Video, error) {
-	logger.Debug(
-		"Creating video",
-		zap.Uint("containerID", new.ContainerID),
-		zap.String("description", new.Description),
-		zap.String("expirationDate", new.ExpirationDate),
-		zap.String("playbackUrl", new.PlaybackURL),
-		zap.String("title", new.Title),
-		zap.String("videoType", string(new.VideoType)),
-	)
+// resolve set id to the ID of the live record of table with externalID, unless externalID is empty.
+func (refs catalogRefs) resolve(table string, externalID string, id *uint) error {
+	if externalID == "" {
+		return nil
+	}
+
+	key := externalKey{externalID: externalID, table: table}
+	if found, ok := refs.ids[key]; ok {
+		*id = found
+
+		return nil
+	}
+
+	record, err := refs.ops.find(table, externalID)
+
+	switch {
+	case refs.failed[key]:
+		err = ErrOwnerNotCreated
+	case errors.Is(err, ErrNotFound):
+		err = ErrUnknownExternalID
+	case err == nil && record.DeletedAt.Valid:
+		err = ErrDeletedOwner
+	}
 
-	video := Video{
-		ContainerID:    new.ContainerID,
-		Description:    new.Description,
-		ExpirationDate: new.ExpirationDate,
-		PlaybackURL:    new.PlaybackURL,
-		Title:          new.Title,
-		VideoType:      VideoType(new.VideoType),
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", strings.TrimSuffix(table, "s"), externalID, err)
 	}
-	result := database.Create(&video)
 
-	return video, result.Error
+	*id = record.ID
+
+	return nil
 }
 
-// DeleteVideo delete the video matching videoID from the database.
-func DeleteVideo(videoID uint) error {
-	logger.Debug("Deleting video", zap.Uint("videoID", videoID))
+/* ************************************************* Catalog results ************************************************ */
+
+// failed whether any record failed to import.
+func (results CatalogResults) failed() bool {
+	for _, result := range slices.Concat(results.Assets, results.Containers, results.Videos) {
+		if result.Err != nil {
+			return true
+		}
+	}
 
-	return database.Delete(&Video{}, videoID).Error
+	return false
 }
 
-// GetVideos get all videos.
-func GetVideos() ([]Video, error) {
-	logger.Debug("Getting all videos")
+/* ************************************************* Content errors ************************************************* */
 
-	var videos []Video
-	result := database.Model(&Video{}).Preload("Assets").Find(&videos)
+// join the errors of the container and its content, or nil if there are none.
+func (errs ContentErrors) join() error {
+	all := slices.Concat(append([][]error{{errs.Container}, errs.Videos, errs.Assets}, errs.VideoAssets...)...)
 
-	return videos, result.Error
+	return errors.Join(all...)
 }
 
-// GetVideosByContainer get all videos matching containerID.
-func GetVideosByContainer(containerID uint) ([]Video, error) {
-	logger.Debug("Getting videos", zap.Uint("containerID", containerID))
+/* ****************************************************** Video ***************************************************** */
 
-	var videos []Video
-	result := database.Model(&Video{}).Preload("Assets").Where("container_id = ?", containerID).Find(&videos)
+// Expired whether the video's expiration date has passed as of now.
+func (video Video) Expired(now time.Time) bool {
+	return video.ExpirationDate != nil && !video.ExpirationDate.After(now)
+}
+
+/* ************************************************** Video patch *************************************************** */
 
-	return videos, result.Error
+// apply set the fields of video that the patch changes.
+func (patch VideoPatch) apply(video *Video) {
+	setIfPresent(&video.ContainerID, patch.ContainerID)
+	setIfPresent(&video.Description, patch.Description)
+	setIfPresent(&video.PlaybackURL, patch.PlaybackURL)
+	setIfPresent(&video.Title, patch.Title)
+	setIfPresent(&video.VideoType, patch.VideoType)
+
+	if patch.ExpirationDate != nil || patch.ClearExpirationDate {
+		video.ExpirationDate = patch.ExpirationDate
+	}
 }
 
-// UpdateVideo update the video in the database.
-func UpdateVideo(update model.UpdateVideo) error {
-	logger.Debug(
-		"Updating video",
-		zap.Uint("containerID", update.ContainerID),
-		zap.Uint("id", update.ID),
-		zap.String("description", update.Description),
-		zap.String("expirationDate", update.ExpirationDate),
-		zap.String("playbackUrl", update.PlaybackURL),
-		zap.String("title", update.Title),
-		zap.String("videoType", string(update.VideoType)),
-	)
+// columns new values of the columns that the patch changes.
+func (patch VideoPatch) columns() map[string]interface{} {
+	columns := map[string]interface{}{}
+	addIfPresent(columns, "container_id", patch.ContainerID)
+	addIfPresent(columns, "description", patch.Description)
+	addIfPresent(columns, "expiration_date", patch.ExpirationDate)
+	addIfPresent(columns, "playback_url", patch.PlaybackURL)
+	addIfPresent(columns, "title", patch.Title)
+	addIfPresent(columns, "video_type", patch.VideoType)
 
-	video := Video{
-		Model:          gorm.Model{ID: update.ID},
-		ContainerID:    update.ContainerID,
-		Description:    update.Description,
-		ExpirationDate: update.ExpirationDate,
-		PlaybackURL:    update.PlaybackURL,
-		Title:          update.Title,
-		VideoType:      VideoType(update.VideoType),
+	if patch.ClearExpirationDate {
+		columns["expiration_date"] = nil
 	}
 
-	return database.Save(&video).Error
+	return columns
+}
+
+/* ************************************************* Video revision ************************************************* */
+
+// apply set the fields of video that RevertVideo reverts to those of the revision.
+func (revision VideoRevision) apply(video *Video) {
+	video.ContainerID = revision.ContainerID
+	video.Description = revision.Description
+	video.ExpirationDate = revision.ExpirationDate
+	video.PlaybackURL = revision.PlaybackURL
+	video.Title = revision.Title
+	video.VideoType = revision.VideoType
+}
+
+// columns new values of the columns that RevertVideo reverts.
+func (revision VideoRevision) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"container_id":    revision.ContainerID,
+		"description":     revision.Description,
+		"expiration_date": revision.ExpirationDate,
+		"playback_url":    revision.PlaybackURL,
+		"title":           revision.Title,
+		"video_type":      revision.VideoType,
+	}
 }
 
 /* *************************************************** Video type *************************************************** */
 
 func (videoType *VideoType) Scan(value interface{}) error {
-	*videoType = VideoType(value.([]byte))
+	text, err := scanText(value)
+	*videoType = VideoType(text)
 
-	return nil
+	return err
 }
 
 func (videoType VideoType) Value() (driver.Value, error) {
 	return string(videoType), nil
 }
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// actorOf actor that ctx names, or AnonymousActor if it names none.
+func actorOf(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return AnonymousActor
+}
+
+// addIfPresent add value to columns as column, unless it is nil.
+func addIfPresent[T any](columns map[string]interface{}, column string, value *T) {
+	if value != nil {
+		columns[column] = *value
+	}
+}
+
+// auditedRecord type name and ID of record, an Asset, Container, or Video.
+func auditedRecord(record interface{}) (string, uint) {
+	switch record := record.(type) {
+	case Asset:
+		return "Asset", record.ID
+	case Container:
+		return "Container", record.ID
+	case Video:
+		return "Video", record.ID
+	default:
+		panic(fmt.Sprintf("unaudited record type %T", record))
+	}
+}
+
+// checkExternalID ErrExternalIDTaken if find finds a record of table with externalID, unless it is nil.
+func checkExternalID(
+	find func(table string, externalID string) (externalRecord, error),
+	table string,
+	externalID *string,
+) error {
+	if externalID == nil {
+		return nil
+	}
+
+	_, err := find(table, *externalID)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return ErrExternalIDTaken
+	}
+}
+
+// createContent create the videos and assets of the created container, and return their errors.
+func createContent(
+	container *Container,
+	continueOnError bool,
+	step batchStep,
+	createVideo func(video *Video) error,
+	createAsset func(asset *Asset) error,
+) ContentErrors {
+	errs := ContentErrors{
+		Assets:      make([]error, len(container.Assets)),
+		VideoAssets: make([][]error, len(container.Videos)),
+		Videos:      make([]error, len(container.Videos)),
+	}
+
+	for i := range container.Videos {
+		video := &container.Videos[i]
+		video.ContainerID = container.ID
+		errs.VideoAssets[i] = make([]error, len(video.Assets))
+
+		errs.Videos[i] = runStep(continueOnError, step, func() error { return createVideo(video) })
+		if errs.Videos[i] != nil {
+			if !continueOnError {
+				return errs
+			}
+
+			for j := range video.Assets {
+				errs.VideoAssets[i][j] = ErrOwnerNotCreated
+			}
+
+			continue
+		}
+
+		errs.VideoAssets[i] = createEach(len(video.Assets), continueOnError, step, func(j int) error {
+			video.Assets[j].ContainerID, video.Assets[j].VideoID = container.ID, video.ID
+
+			return createAsset(&video.Assets[j])
+		})
+		if !continueOnError && errors.Join(errs.VideoAssets[i]...) != nil {
+			return errs
+		}
+	}
+
+	errs.Assets = createEach(len(container.Assets), continueOnError, step, func(i int) error {
+		container.Assets[i].ContainerID, container.Assets[i].VideoID = container.ID, 0
+
+		return createAsset(&container.Assets[i])
+	})
+
+	return errs
+}
+
+// createEach create count records with create, passed the index of each, and return their errors.
+func createEach(count int, continueOnError bool, step batchStep, create func(i int) error) []error {
+	errs := make([]error, count)
+
+	for i := range count {
+		errs[i] = runStep(continueOnError, step, func() error { return create(i) })
+		if errs[i] != nil && !continueOnError {
+			break
+		}
+	}
+
+	return errs
+}
+
+// importCatalog import the containers, videos, and assets of catalog with ops, and return their results.
+func importCatalog(catalog *Catalog, step batchStep, ops catalogOps) CatalogResults {
+	results := CatalogResults{
+		Assets:     make([]ImportResult, len(catalog.Assets)),
+		Containers: make([]ImportResult, len(catalog.Containers)),
+		Videos:     make([]ImportResult, len(catalog.Videos)),
+	}
+	refs := catalogRefs{failed: map[externalKey]bool{}, ids: map[externalKey]uint{}, ops: ops}
+
+	for i := range catalog.Containers {
+		container := &catalog.Containers[i]
+
+		results.Containers[i] = importRecord(step, func() (bool, error) {
+			return upsert(
+				ops,
+				"containers",
+				container.ExternalID,
+				func(id uint, version uint) { container.ID, container.Version = id, version },
+				func() error { return ops.createContainer(container) },
+				func() error { return ops.updateContainer(container) },
+			)
+		})
+		refs.add("containers", container.ExternalID, container.ID, results.Containers[i].Err)
+	}
+
+	for i := range catalog.Videos {
+		video := &catalog.Videos[i]
+
+		results.Videos[i] = importRecord(step, func() (bool, error) {
+			if err := refs.resolve("containers", video.ContainerExternalID, &video.ContainerID); err != nil {
+				return false, err
+			}
+
+			return upsert(
+				ops,
+				"videos",
+				video.ExternalID,
+				func(id uint, version uint) { video.ID, video.Version = id, version },
+				func() error { return ops.createVideo(&video.Video) },
+				func() error { return ops.updateVideo(&video.Video) },
+			)
+		})
+		refs.add("videos", video.ExternalID, video.ID, results.Videos[i].Err)
+	}
+
+	for i := range catalog.Assets {
+		asset := &catalog.Assets[i]
+
+		results.Assets[i] = importRecord(step, func() (bool, error) {
+			err := errors.Join(
+				refs.resolve("containers", asset.ContainerExternalID, &asset.ContainerID),
+				refs.resolve("videos", asset.VideoExternalID, &asset.VideoID),
+			)
+			if err != nil {
+				return false, err
+			}
+
+			return upsert(
+				ops,
+				"assets",
+				asset.ExternalID,
+				func(id uint, version uint) { asset.ID, asset.Version = id, version },
+				func() error { return ops.createAsset(&asset.Asset) },
+				func() error { return ops.updateAsset(&asset.Asset) },
+			)
+		})
+	}
+
+	return results
+}
+
+// importRecord run upsert, which imports one record of a catalog, as a step, and return its result.
+func importRecord(step batchStep, upsert func() (bool, error)) ImportResult {
+	var result ImportResult
+
+	result.Err = step(func() error {
+		var err error
+		result.Updated, err = upsert()
+
+		return err
+	})
+
+	return result
+}
+
+// newAuditEvent event recording operation on a record by the actor of ctx, or false if it left the record as it was.
+func newAuditEvent(
+	ctx context.Context,
+	operation AuditOperation,
+	before interface{},
+	after interface{},
+) (AuditEvent, bool, error) {
+	record := after
+	if record == nil {
+		record = before
+	}
+
+	if record == nil {
+		return AuditEvent{}, false, nil
+	}
+
+	beforeSnapshot, err := snapshot(before)
+	if err != nil {
+		return AuditEvent{}, false, err
+	}
+
+	afterSnapshot, err := snapshot(after)
+	if err != nil {
+		return AuditEvent{}, false, err
+	}
+
+	if beforeSnapshot != nil && afterSnapshot != nil && *beforeSnapshot == *afterSnapshot {
+		return AuditEvent{}, false, nil
+	}
+
+	entityType, entityID := auditedRecord(record)
+
+	return AuditEvent{
+		Actor:      actorOf(ctx),
+		After:      afterSnapshot,
+		Before:     beforeSnapshot,
+		EntityID:   entityID,
+		EntityType: entityType,
+		OccurredAt: time.Now().UTC(),
+		Operation:  operation,
+	}, true, nil
+}
+
+// newVideoRevision revision recording the state of video after the change that event records. Stores number it.
+func newVideoRevision(event AuditEvent, video Video) VideoRevision {
+	revision := VideoRevision{
+		Actor:          event.Actor,
+		ArchivedAt:     video.ArchivedAt,
+		ContainerID:    video.ContainerID,
+		CreatedAt:      event.OccurredAt,
+		Description:    video.Description,
+		ExpirationDate: video.ExpirationDate,
+		Operation:      event.Operation,
+		PlaybackURL:    video.PlaybackURL,
+		Title:          video.Title,
+		VideoID:        video.ID,
+		VideoType:      video.VideoType,
+	}
+
+	if video.DeletedAt.Valid {
+		revision.DeletedAt = &video.DeletedAt.Time
+	}
+
+	return revision
+}
+
+// nullableID id as a column value, with 0, which stands for no row, as NULL so that foreign keys allow it.
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+// runStep run create by step if continueOnError, and directly otherwise, as the whole batch then fails with it.
+func runStep(continueOnError bool, step batchStep, create func() error) error {
+	if continueOnError {
+		return step(create)
+	}
+
+	return create()
+}
+
+// scanText read an enum column, which drivers return as either bytes or a string.
+func scanText(value interface{}) (string, error) {
+	switch text := value.(type) {
+	case []byte:
+		return string(text), nil
+	case string:
+		return text, nil
+	default:
+		return "", fmt.Errorf("unsupported enum value type %T", value)
+	}
+}
+
+// setIfPresent set field to value, unless it is nil.
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// snapshot JSON snapshot of record, or nil if it is nil.
+func snapshot(record interface{}) (*string, error) {
+	if record == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.Internal, err, "failed to snapshot record")
+	}
+
+	text := string(raw)
+
+	return &text, nil
+}
+
+// sortIssues issues ordered by ID, then problem, and never nil.
+func sortIssues(issues []IntegrityIssue) []IntegrityIssue {
+	if issues == nil {
+		return []IntegrityIssue{}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].ID != issues[j].ID {
+			return issues[i].ID < issues[j].ID
+		}
+
+		return issues[i].Problem < issues[j].Problem
+	})
+
+	return issues
+}
+
+// tryLock take the lock matching key if it is free.
+func (locks *localLocks) tryLock(key int64) (func(), bool, error) {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	if locks.held[key] {
+		return nil, false, nil
+	}
+
+	if locks.held == nil {
+		locks.held = make(map[int64]bool)
+	}
+
+	locks.held[key] = true
+
+	unlock := func() {
+		locks.mutex.Lock()
+		defer locks.mutex.Unlock()
+
+		delete(locks.held, key)
+	}
+
+	return unlock, true, nil
+}
+
+// upsert create a record with create, or update the live record of table with externalID with update.
+func upsert(
+	ops catalogOps,
+	table string,
+	externalID *string,
+	identify func(id uint, version uint),
+	create func() error,
+	update func() error,
+) (bool, error) {
+	if externalID == nil {
+		return false, create()
+	}
+
+	existing, err := ops.find(table, *externalID)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return false, create()
+	case err != nil:
+		return false, err
+	case existing.DeletedAt.Valid:
+		return false, ErrDeletedExternalID
+	}
+
+	identify(existing.ID, existing.Version)
+
+	return true, update()
+}
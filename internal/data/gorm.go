@@ -0,0 +1,1750 @@
+package data
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"moul.io/zapgorm2"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSavepoint name of the savepoint that each creation of a batch that continues on error runs in.
+	batchSavepoint    = "batch_record"
+	defaultSqlitePath = "rocket-container.db"
+)
+
+// errBatchFailed returned to roll back a batch transaction when one of its records fails.
+var errBatchFailed = errors.New("batch failed")
+
+// registerSqliteLower replace SQLite's LOWER, which only folds ASCII, with strings.ToLower, as the memory store folds.
+var registerSqliteLower = sync.OnceFunc(func() {
+	sqlitedriver.MustRegisterDeterministicScalarFunction(
+		"lower",
+		1,
+		func(ctx *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+			if text, ok := args[0].(string); ok {
+				return strings.ToLower(text), nil
+			}
+
+			return args[0], nil
+		},
+	)
+})
+
+// gormStore Store backed by a GORM database.
+type gormStore struct {
+	db     *gorm.DB
+	locks  localLocks
+	logger *zap.Logger
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// NewGormStore create a Store backed by db.
+func NewGormStore(db *gorm.DB, logger *zap.Logger) Store {
+	return &gormStore{db: db, logger: logger}
+}
+
+// openGorm connect to the database behind dialector.
+func openGorm(dialector gorm.Dialector, logger *zap.Logger) (*gorm.DB, error) {
+	gormLogger := zapgorm2.New(logger)
+	gormLogger.SetAsDefault()
+
+	if dialector.Name() == "sqlite" {
+		registerSqliteLower()
+	}
+
+	// SQLite compares times as text, so every timestamp is written in UTC for range filters and sorting to hold.
+	// TranslateError reports constraint violations as GORM errors, which translateError maps to domain errors.
+	return gorm.Open(
+		dialector,
+		&gorm.Config{Logger: gormLogger, NowFunc: func() time.Time { return time.Now().UTC() }, TranslateError: true},
+	)
+}
+
+// postgresDialector Postgres dialector configured by the DB_* environment variables.
+func postgresDialector() gorm.Dialector {
+	dbHost := os.Getenv("DB_HOST")
+	dbName := os.Getenv("DB_NAME")
+	dbPass := os.Getenv("DB_PASSWORD")
+	dbPort := os.Getenv("DB_PORT")
+	dbUser := os.Getenv("DB_USER")
+
+	dsn := "host=" + dbHost + " user=" + dbUser + " password=" + dbPass + " dbname=" + dbName + " port=" + dbPort
+
+	return postgres.Open(dsn)
+}
+
+// sqliteDialector SQLite dialector for the DB_PATH file, with foreign keys enforced.
+func sqliteDialector() gorm.Dialector {
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = defaultSqlitePath
+	}
+
+	return sqlite.Open(dbPath + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+}
+
+/* ****************************************************** Asset ***************************************************** */
+
+// CreateAsset create the asset in the database.
+func (store *gormStore) CreateAsset(ctx context.Context, asset *Asset) error {
+	store.logger.Debug(
+		"Creating asset",
+		zap.String("assetType", string(asset.AssetType)),
+		zap.Uint("containerID", asset.ContainerID),
+		zap.String("name", asset.Name),
+		zap.String("url", asset.URL),
+		zap.Uint("videoID", asset.VideoID),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createAsset(ctx, tx, asset)
+	})
+
+	return translateError(err)
+}
+
+// CreateAssets create the assets in the database in one transaction.
+func (store *gormStore) CreateAssets(ctx context.Context, assets []Asset, continueOnError bool) ([]error, error) {
+	store.logger.Debug("Creating assets", zap.Int("count", len(assets)), zap.Bool("continueOnError", continueOnError))
+
+	var errs []error
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		errs = createEach(len(assets), continueOnError, savepointStep(tx), func(i int) error {
+			return translateError(createAsset(ctx, tx, &assets[i]))
+		})
+
+		return batchResult(continueOnError, errors.Join(errs...))
+	})
+
+	return errs, batchError(err)
+}
+
+// DeleteAsset delete the asset matching assetID from the database.
+func (store *gormStore) DeleteAsset(ctx context.Context, assetID uint) error {
+	store.logger.Debug("Deleting asset", zap.Uint("assetID", assetID))
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return audited[Asset](ctx, tx, AuditDelete, []uint{assetID}, func() error {
+			return requireRows(tx.Delete(&Asset{}, assetID))
+		})
+	})
+
+	return translateError(err)
+}
+
+// GetAssets get a page of assets matching containerID and filter.
+func (store *gormStore) GetAssets(
+	ctx context.Context,
+	containerID uint,
+	filter AssetFilter,
+	order Order,
+	page Page,
+) (Connection[Asset], error) {
+	store.logger.Debug(
+		"Getting assets",
+		zap.Uint("containerID", containerID),
+		zap.Any("filter", filter),
+		zap.Any("order", order),
+	)
+
+	query := store.db.WithContext(ctx).
+		Model(&Asset{}).
+		Scopes(assetScope(filter, time.Now())).
+		Where("assets.container_id = ?", containerID)
+
+	return paginateQuery(query, assetKeyset, page, order, nil)
+}
+
+// GetAssetsByIDs get the assets matching assetIDs from the database.
+func (store *gormStore) GetAssetsByIDs(ctx context.Context, assetIDs []uint) (map[uint]Asset, error) {
+	store.logger.Debug("Getting assets by IDs", zap.Uints("assetIDs", assetIDs))
+
+	return findByIDs(store.db.WithContext(ctx), assetIDs, assetKeyset.id)
+}
+
+// GetAssetsByVideos get the assets of each video matching videoIDs from the database.
+func (store *gormStore) GetAssetsByVideos(ctx context.Context, videoIDs []uint) (map[uint][]Asset, error) {
+	store.logger.Debug("Getting assets by videos", zap.Uints("videoIDs", videoIDs))
+
+	var assets []Asset
+	if err := store.db.WithContext(ctx).Where("video_id IN ?", videoIDs).Order("id").Find(&assets).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	byVideo := make(map[uint][]Asset, len(videoIDs))
+
+	for _, asset := range assets {
+		byVideo[asset.VideoID] = append(byVideo[asset.VideoID], asset)
+	}
+
+	return byVideo, nil
+}
+
+// GetDeletedAssets get a page of deleted assets matching containerID from the database.
+func (store *gormStore) GetDeletedAssets(ctx context.Context, containerID uint, page Page) (Connection[Asset], error) {
+	store.logger.Debug("Getting deleted assets", zap.Uint("containerID", containerID))
+
+	query := store.db.WithContext(ctx).
+		Unscoped().
+		Model(&Asset{}).
+		Scopes(containerScope("assets", containerID)).
+		Where("assets.deleted_at IS NOT NULL")
+
+	return paginateQuery(query, assetKeyset, page, Order{}, nil)
+}
+
+// PatchAsset apply patch to the asset matching assetID in the database.
+func (store *gormStore) PatchAsset(ctx context.Context, assetID uint, patch AssetPatch) (Asset, error) {
+	store.logger.Debug("Patching asset", zap.Uint("assetID", assetID), zap.Any("patch", patch))
+
+	var asset Asset
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return audited[Asset](ctx, tx, AuditUpdate, []uint{assetID}, func() error {
+			if err := updateVersionedRow(tx, &asset, assetID, patch.Version, patch.columns()); err != nil {
+				return err
+			}
+
+			return checkAssetOwners(tx, asset)
+		})
+	})
+
+	return asset, translateError(err)
+}
+
+// RestoreAsset restore the deleted asset matching assetID in the database.
+func (store *gormStore) RestoreAsset(ctx context.Context, assetID uint) (Asset, error) {
+	store.logger.Debug("Restoring asset", zap.Uint("assetID", assetID))
+
+	var asset Asset
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&asset, assetID).Error; err != nil {
+			return err
+		}
+
+		if err := requireLiveOwners(tx, asset.ContainerID, asset.VideoID); err != nil {
+			return err
+		}
+
+		return audited[Asset](ctx, tx, AuditRestore, []uint{assetID}, func() error {
+			return updateRow(tx.Unscoped(), &asset, assetID, map[string]interface{}{"deleted_at": nil})
+		})
+	})
+
+	return asset, translateError(err)
+}
+
+// UpdateAsset update the asset in the database.
+func (store *gormStore) UpdateAsset(ctx context.Context, asset *Asset) error {
+	store.logger.Debug(
+		"Updating asset",
+		zap.String("assetType", string(asset.AssetType)),
+		zap.Uint("containerID", asset.ContainerID),
+		zap.Uint("id", asset.ID),
+		zap.String("name", asset.Name),
+		zap.String("url", asset.URL),
+		zap.Uint("videoID", asset.VideoID),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateAsset(ctx, tx, asset)
+	})
+
+	return translateError(err)
+}
+
+/* *************************************************** Container **************************************************** */
+
+// CreateContainer create the container in the database.
+func (store *gormStore) CreateContainer(ctx context.Context, container *Container) error {
+	store.logger.Debug(
+		"Creating container",
+		zap.String("description", container.Description),
+		zap.String("name", container.Name),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createContainer(ctx, tx, container)
+	})
+
+	return translateError(err)
+}
+
+// CreateContainerWithContent create the container and its content in the database in one transaction.
+func (store *gormStore) CreateContainerWithContent(
+	ctx context.Context,
+	container *Container,
+	continueOnError bool,
+) (ContentErrors, error) {
+	store.logger.Debug(
+		"Creating container with content",
+		zap.String("name", container.Name),
+		zap.Int("videos", len(container.Videos)),
+		zap.Int("assets", len(container.Assets)),
+		zap.Bool("continueOnError", continueOnError),
+	)
+
+	var errs ContentErrors
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if errs.Container = translateError(createContainer(ctx, tx, container)); errs.Container != nil {
+			return errBatchFailed
+		}
+
+		errs = createContent(
+			container,
+			continueOnError,
+			savepointStep(tx),
+			func(video *Video) error { return translateError(createVideo(ctx, tx, video)) },
+			func(asset *Asset) error { return translateError(createAsset(ctx, tx, asset)) },
+		)
+
+		return batchResult(continueOnError, errs.join())
+	})
+
+	return errs, batchError(err)
+}
+
+// DeleteContainer delete the container matching containerID from the database, along with its contents per onContents.
+func (store *gormStore) DeleteContainer(ctx context.Context, containerID uint, onContents DeletePolicy) error {
+	store.logger.Debug(
+		"Deleting container",
+		zap.Uint("containerID", containerID),
+		zap.String("onContents", string(onContents)),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return audited[Container](ctx, tx, AuditDelete, []uint{containerID}, func() error {
+			return deleteContainer(ctx, tx, containerID, onContents)
+		})
+	})
+
+	return translateError(err)
+}
+
+// GetContainer get the container matching containerID.
+func (store *gormStore) GetContainer(ctx context.Context, containerID uint) (Container, error) {
+	store.logger.Debug("Getting container", zap.Uint("containerID", containerID))
+
+	var container Container
+	result := store.db.WithContext(ctx).First(&container, containerID)
+
+	return container, translateError(result.Error)
+}
+
+// GetContainers get all containers.
+func (store *gormStore) GetContainers(ctx context.Context, page Page) (Connection[Container], error) {
+	store.logger.Debug("Getting containers")
+
+	return paginateQuery(store.db.WithContext(ctx).Model(&Container{}), containerKeyset, page, Order{}, nil)
+}
+
+// GetContainersByIDs get the containers matching containerIDs from the database.
+func (store *gormStore) GetContainersByIDs(ctx context.Context, containerIDs []uint) (map[uint]Container, error) {
+	store.logger.Debug("Getting containers by IDs", zap.Uints("containerIDs", containerIDs))
+
+	return findByIDs(store.db.WithContext(ctx), containerIDs, containerKeyset.id)
+}
+
+// GetDeletedContainers get a page of deleted containers from the database.
+func (store *gormStore) GetDeletedContainers(ctx context.Context, page Page) (Connection[Container], error) {
+	store.logger.Debug("Getting deleted containers")
+
+	query := store.db.WithContext(ctx).Unscoped().Model(&Container{}).Where("containers.deleted_at IS NOT NULL")
+
+	return paginateQuery(query, containerKeyset, page, Order{}, nil)
+}
+
+// RestoreContainer restore the deleted container matching containerID in the database.
+func (store *gormStore) RestoreContainer(ctx context.Context, containerID uint, withContents bool) (Container, error) {
+	store.logger.Debug(
+		"Restoring container",
+		zap.Uint("containerID", containerID),
+		zap.Bool("withContents", withContents),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var container Container
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&container, containerID).Error; err != nil {
+			return err
+		}
+
+		if withContents {
+			if err := restoreContents(ctx, tx, containerID); err != nil {
+				return err
+			}
+		}
+
+		return audited[Container](ctx, tx, AuditRestore, []uint{containerID}, func() error {
+			return updateRow(tx.Unscoped(), &container, containerID, map[string]interface{}{"deleted_at": nil})
+		})
+	})
+	if err != nil {
+		return Container{}, translateError(err)
+	}
+
+	return store.GetContainer(ctx, containerID)
+}
+
+// UpdateContainer update the container in the database.
+func (store *gormStore) UpdateContainer(ctx context.Context, container *Container) error {
+	store.logger.Debug(
+		"Updating container",
+		zap.String("description", container.Description),
+		zap.Uint("id", container.ID),
+		zap.String("name", container.Name),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateContainer(ctx, tx, container)
+	})
+
+	return translateError(err)
+}
+
+/* ***************************************************** Video ****************************************************** */
+
+// CreateVideo create the video in the database.
+func (store *gormStore) CreateVideo(ctx context.Context, video *Video) error {
+	store.logger.Debug(
+		"Creating video",
+		zap.Uint("containerID", video.ContainerID),
+		zap.String("description", video.Description),
+		zap.Timep("expirationDate", video.ExpirationDate),
+		zap.String("playbackUrl", video.PlaybackURL),
+		zap.String("title", video.Title),
+		zap.String("videoType", string(video.VideoType)),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createVideo(ctx, tx, video)
+	})
+
+	return translateError(err)
+}
+
+// CreateVideos create the videos in the database in one transaction.
+func (store *gormStore) CreateVideos(ctx context.Context, videos []Video, continueOnError bool) ([]error, error) {
+	store.logger.Debug("Creating videos", zap.Int("count", len(videos)), zap.Bool("continueOnError", continueOnError))
+
+	var errs []error
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		errs = createEach(len(videos), continueOnError, savepointStep(tx), func(i int) error {
+			return translateError(createVideo(ctx, tx, &videos[i]))
+		})
+
+		return batchResult(continueOnError, errors.Join(errs...))
+	})
+
+	return errs, batchError(err)
+}
+
+// DeleteVideo delete the video matching videoID from the database, applying onAssets to its assets.
+func (store *gormStore) DeleteVideo(ctx context.Context, videoID uint, onAssets DeletePolicy) error {
+	store.logger.Debug(
+		"Deleting video",
+		zap.Uint("videoID", videoID),
+		zap.String("onAssets", string(onAssets)),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return audited[Video](ctx, tx, AuditDelete, []uint{videoID}, func() error {
+			return deleteVideo(ctx, tx, videoID, onAssets)
+		})
+	})
+
+	return translateError(err)
+}
+
+// GetDeletedVideos get a page of deleted videos matching containerID from the database.
+func (store *gormStore) GetDeletedVideos(ctx context.Context, containerID uint, page Page) (Connection[Video], error) {
+	store.logger.Debug("Getting deleted videos", zap.Uint("containerID", containerID))
+
+	query := store.db.WithContext(ctx).
+		Unscoped().
+		Model(&Video{}).
+		Scopes(containerScope("videos", containerID)).
+		Where("videos.deleted_at IS NOT NULL")
+
+	return paginateQuery(query, videoKeyset, page, Order{}, nil)
+}
+
+// GetVideoRevision get revision number revision of the video matching videoID from the database.
+func (store *gormStore) GetVideoRevision(ctx context.Context, videoID uint, revision uint) (VideoRevision, error) {
+	store.logger.Debug("Getting video revision", zap.Uint("videoID", videoID), zap.Uint("revision", revision))
+
+	var found VideoRevision
+	result := store.db.WithContext(ctx).Where("video_id = ? AND revision = ?", videoID, revision).First(&found)
+
+	return found, translateError(result.Error)
+}
+
+// GetVideoRevisions get a page of the revisions of the video matching videoID from the database.
+func (store *gormStore) GetVideoRevisions(
+	ctx context.Context,
+	videoID uint,
+	page Page,
+) (Connection[VideoRevision], error) {
+	store.logger.Debug("Getting video revisions", zap.Uint("videoID", videoID))
+
+	query := store.db.WithContext(ctx).Model(&VideoRevision{}).Where("video_revisions.video_id = ?", videoID)
+
+	return paginateQuery(query, videoRevisionKeyset, page, Order{}, nil)
+}
+
+// GetVideosByContainer get a page of videos matching containerID and filter.
+func (store *gormStore) GetVideosByContainer(
+	ctx context.Context,
+	containerID uint,
+	filter VideoFilter,
+	order Order,
+	page Page,
+) (Connection[Video], error) {
+	store.logger.Debug(
+		"Getting videos",
+		zap.Uint("containerID", containerID),
+		zap.Any("filter", filter),
+		zap.Any("order", order),
+	)
+
+	query := store.db.WithContext(ctx).
+		Model(&Video{}).
+		Scopes(videoScope(filter, time.Now())).
+		Where("videos.container_id = ?", containerID)
+
+	return paginateQuery(query, videoKeyset, page, order, nil)
+}
+
+// GetVideosByIDs get the videos matching videoIDs from the database.
+func (store *gormStore) GetVideosByIDs(ctx context.Context, videoIDs []uint) (map[uint]Video, error) {
+	store.logger.Debug("Getting videos by IDs", zap.Uints("videoIDs", videoIDs))
+
+	return findByIDs(store.db.WithContext(ctx), videoIDs, videoKeyset.id)
+}
+
+// PatchVideo apply patch to the video matching videoID in the database.
+func (store *gormStore) PatchVideo(ctx context.Context, videoID uint, patch VideoPatch) (Video, error) {
+	store.logger.Debug("Patching video", zap.Uint("videoID", videoID), zap.Any("patch", patch))
+
+	var video Video
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return auditedVideoUpdate(ctx, tx, videoID, AuditUpdate, func() error {
+			if err := updateVersionedRow(tx, &video, videoID, patch.Version, patch.columns()); err != nil {
+				return err
+			}
+
+			return checkContainer(tx, video.ContainerID)
+		})
+	})
+
+	return video, translateError(err)
+}
+
+// RestoreVideo restore the deleted video matching videoID in the database.
+func (store *gormStore) RestoreVideo(ctx context.Context, videoID uint, withAssets bool) (Video, error) {
+	store.logger.Debug("Restoring video", zap.Uint("videoID", videoID), zap.Bool("withAssets", withAssets))
+
+	var video Video
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&video, videoID).Error; err != nil {
+			return err
+		}
+
+		if err := requireLiveOwners(tx, video.ContainerID, 0); err != nil {
+			return err
+		}
+
+		if withAssets {
+			assets := tx.Unscoped().
+				Model(&Asset{}).
+				Where("video_id = ? AND deleted_at = (SELECT deleted_at FROM videos WHERE id = ?)", videoID, videoID)
+
+			assetIDs, err := findIDs(assets)
+			if err != nil {
+				return err
+			}
+
+			err = audited[Asset](ctx, tx, AuditRestore, assetIDs, func() error {
+				return tx.Unscoped().Model(&Asset{}).Where("id IN ?", assetIDs).Update("deleted_at", nil).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return audited[Video](ctx, tx, AuditRestore, []uint{videoID}, func() error {
+			return updateRow(tx.Unscoped(), &video, videoID, map[string]interface{}{"deleted_at": nil})
+		})
+	})
+
+	return video, translateError(err)
+}
+
+// RevertVideo revert the video matching videoID in the database to its revision number revision.
+func (store *gormStore) RevertVideo(ctx context.Context, videoID uint, revision uint) (Video, error) {
+	store.logger.Debug("Reverting video", zap.Uint("videoID", videoID), zap.Uint("revision", revision))
+
+	var video Video
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target VideoRevision
+		if err := tx.Where("video_id = ? AND revision = ?", videoID, revision).First(&target).Error; err != nil {
+			return err
+		}
+
+		if err := requireLiveOwners(tx, target.ContainerID, 0); err != nil {
+			return err
+		}
+
+		return auditedVideoUpdate(ctx, tx, videoID, AuditRevert, func() error {
+			return updateRow(tx, &video, videoID, target.columns())
+		})
+	})
+
+	return video, translateError(err)
+}
+
+// UpdateVideo update the video in the database.
+func (store *gormStore) UpdateVideo(ctx context.Context, video *Video) error {
+	store.logger.Debug(
+		"Updating video",
+		zap.Uint("containerID", video.ContainerID),
+		zap.Uint("id", video.ID),
+		zap.String("description", video.Description),
+		zap.Timep("expirationDate", video.ExpirationDate),
+		zap.String("playbackUrl", video.PlaybackURL),
+		zap.String("title", video.Title),
+		zap.String("videoType", string(video.VideoType)),
+	)
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateVideo(ctx, tx, video)
+	})
+
+	return translateError(err)
+}
+
+/* ***************************************************** Search ***************************************************** */
+
+// Search search videos and assets in the database, by full-text search on Postgres and in-process otherwise.
+func (store *gormStore) Search(
+	ctx context.Context,
+	query string,
+	filter SearchFilter,
+	limit int,
+) ([]SearchResult, error) {
+	store.logger.Debug("Searching", zap.String("query", query), zap.Any("filter", filter), zap.Int("limit", limit))
+
+	if store.db.Dialector.Name() == "postgres" {
+		return store.searchFullText(ctx, query, filter, limit)
+	}
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	now := time.Now()
+	db := store.db.WithContext(ctx)
+
+	// Narrow the candidates in SQL; rankSearchResults then keeps only those where every term starts a word.
+	videoQuery := db.Scopes(
+		videoScope(VideoFilter{IncludeExpired: filter.IncludeExpired}, now),
+		containerScope("videos", filter.ContainerID),
+	)
+	assetQuery := db.Scopes(
+		assetScope(AssetFilter{IncludeExpired: filter.IncludeExpired}, now),
+		containerScope("assets", filter.ContainerID),
+	)
+
+	for _, term := range terms {
+		pattern := containsPattern(term)
+		videoQuery = videoQuery.Where(
+			`(LOWER(videos.title) LIKE ? ESCAPE '\' OR LOWER(videos.description) LIKE ? ESCAPE '\')`,
+			pattern,
+			pattern,
+		)
+		assetQuery = assetQuery.Where(`LOWER(assets.name) LIKE ? ESCAPE '\'`, pattern)
+	}
+
+	var videos []Video
+	if err := videoQuery.Find(&videos).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	var assets []Asset
+	if err := assetQuery.Find(&assets).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return rankSearchResults(terms, videos, assets, limit), nil
+}
+
+/* *************************************************** Integrity **************************************************** */
+
+// IntegrityReport find the live rows in the database whose references are broken.
+func (store *gormStore) IntegrityReport(ctx context.Context) (IntegrityReport, error) {
+	store.logger.Debug("Reporting integrity")
+
+	db := store.db.WithContext(ctx)
+
+	assetContainers, err := findBrokenReferences(db, "assets", "container_id", "containers")
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+
+	assetVideos, err := findBrokenReferences(db, "assets", "video_id", "videos")
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+
+	var mismatches []IntegrityIssue
+	err = db.Table("assets").
+		Select("assets.id AS id, ? AS problem, assets.video_id AS referenced_id", VideoContainerMismatch).
+		Joins("JOIN videos ON videos.id = assets.video_id").
+		Where("assets.deleted_at IS NULL AND videos.deleted_at IS NULL AND videos.container_id <> assets.container_id").
+		Scan(&mismatches).
+		Error
+	if err != nil {
+		return IntegrityReport{}, translateError(err)
+	}
+
+	videoContainers, err := findBrokenReferences(db, "videos", "container_id", "containers")
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+
+	return IntegrityReport{
+		Assets: sortIssues(append(append(assetContainers, assetVideos...), mismatches...)),
+		Videos: sortIssues(videoContainers),
+	}, nil
+}
+
+/* ***************************************************** Audit ****************************************************** */
+
+// GetAuditEvents get a page of the audit events in the database matching filter.
+func (store *gormStore) GetAuditEvents(
+	ctx context.Context,
+	filter AuditFilter,
+	page Page,
+) (Connection[AuditEvent], error) {
+	store.logger.Debug("Getting audit events", zap.Any("filter", filter))
+
+	query := store.db.WithContext(ctx).Model(&AuditEvent{})
+
+	if filter.Actor != "" {
+		query = query.Where("audit_events.actor = ?", filter.Actor)
+	}
+
+	if filter.EntityType != "" {
+		query = query.Where("audit_events.entity_type = ?", filter.EntityType)
+	}
+
+	if filter.EntityID != 0 {
+		query = query.Where("audit_events.entity_id = ?", filter.EntityID)
+	}
+
+	query = timeRangeScope(query, "audit_events.occurred_at", filter.Since, nil)
+
+	return paginateQuery(query, auditEventKeyset, page, Order{}, nil)
+}
+
+/* ***************************************************** Import ***************************************************** */
+
+// ImportCatalog import catalog into the database in one transaction, in which each record has its own savepoint.
+func (store *gormStore) ImportCatalog(
+	ctx context.Context,
+	catalog *Catalog,
+	options ImportOptions,
+) (CatalogResults, error) {
+	store.logger.Debug(
+		"Importing catalog",
+		zap.Int("containers", len(catalog.Containers)),
+		zap.Int("videos", len(catalog.Videos)),
+		zap.Int("assets", len(catalog.Assets)),
+		zap.Bool("continueOnError", options.ContinueOnError),
+		zap.Bool("dryRun", options.DryRun),
+	)
+
+	var results CatalogResults
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		results = importCatalog(catalog, savepointStep(tx), catalogOps{
+			createAsset: func(asset *Asset) error { return translateError(createAsset(ctx, tx, asset)) },
+			createContainer: func(container *Container) error {
+				return translateError(createContainer(ctx, tx, container))
+			},
+			createVideo: func(video *Video) error { return translateError(createVideo(ctx, tx, video)) },
+			find:        externalIDFinder(tx),
+			updateAsset: func(asset *Asset) error { return translateError(updateAsset(ctx, tx, asset)) },
+			updateContainer: func(container *Container) error {
+				return translateError(updateContainer(ctx, tx, container))
+			},
+			updateVideo: func(video *Video) error { return translateError(updateVideo(ctx, tx, video)) },
+		})
+
+		if options.DryRun || (!options.ContinueOnError && results.failed()) {
+			return errBatchFailed
+		}
+
+		return nil
+	})
+
+	return results, batchError(err)
+}
+
+/* ***************************************************** Trash ****************************************************** */
+
+// Purge permanently delete the assets, containers, and videos deleted before deletedBefore from the database.
+func (store *gormStore) Purge(ctx context.Context, deletedBefore time.Time) (PurgeResult, error) {
+	store.logger.Debug("Purging", zap.Time("deletedBefore", deletedBefore))
+
+	var result PurgeResult
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Each query runs after the previous purge, so a video or container whose last reference was just purged goes
+		// too.
+		assetQuery := tx.Unscoped().Where("deleted_at < ?", deletedBefore.UTC())
+		videoQuery := tx.Unscoped().
+			Where("deleted_at < ?", deletedBefore.UTC()).
+			Where("NOT EXISTS (SELECT 1 FROM assets WHERE assets.video_id = videos.id)")
+		containerQuery := tx.Unscoped().
+			Where("deleted_at < ?", deletedBefore.UTC()).
+			Where("NOT EXISTS (SELECT 1 FROM assets WHERE assets.container_id = containers.id)").
+			Where("NOT EXISTS (SELECT 1 FROM videos WHERE videos.container_id = containers.id)")
+
+		assets, err := purgeRows[Asset](ctx, tx, assetQuery)
+		if err != nil {
+			return err
+		}
+
+		videos, err := purgeRows[Video](ctx, tx, videoQuery)
+		if err != nil {
+			return err
+		}
+
+		containers, err := purgeRows[Container](ctx, tx, containerQuery)
+		if err != nil {
+			return err
+		}
+
+		result = PurgeResult{Assets: assets, Containers: containers, Videos: videos}
+
+		return nil
+	})
+
+	return result, translateError(err)
+}
+
+/* ***************************************************** Expiry ***************************************************** */
+
+// ExpireVideo apply action to the expired video matching videoID and mark it processed as of now.
+func (store *gormStore) ExpireVideo(ctx context.Context, videoID uint, action ExpiryAction, now time.Time) error {
+	store.logger.Debug("Expiring video", zap.Uint("videoID", videoID), zap.String("action", string(action)))
+
+	err := store.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return audited[Video](ctx, tx, AuditExpire, []uint{videoID}, func() error {
+			columns := map[string]interface{}{"expiry_processed_at": now.UTC()}
+			if action == ExpiryArchive {
+				columns["archived_at"] = now.UTC()
+			}
+
+			result := tx.Model(&Video{}).Where("id = ? AND expiry_processed_at IS NULL", videoID).UpdateColumns(columns)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+
+			if action == ExpiryDelete {
+				return tx.Delete(&Video{}, videoID).Error
+			}
+
+			return nil
+		})
+	})
+
+	return translateError(err)
+}
+
+// GetExpiredVideos get up to limit videos that expired as of now and have not been processed.
+func (store *gormStore) GetExpiredVideos(ctx context.Context, now time.Time, limit int) ([]Video, error) {
+	store.logger.Debug("Getting expired videos", zap.Time("now", now), zap.Int("limit", limit))
+
+	var videos []Video
+	result := store.db.WithContext(ctx).
+		Where("expiration_date <= ? AND expiry_processed_at IS NULL", now.UTC()).
+		Order("id").
+		Limit(limit).
+		Find(&videos)
+
+	return videos, translateError(result.Error)
+}
+
+// TryLock take a session-level advisory lock on Postgres, or a process-local lock on other databases.
+func (store *gormStore) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	if store.db.Dialector.Name() != "postgres" {
+		return store.locks.tryLock(key)
+	}
+
+	sqlDB, err := store.db.DB()
+	if err != nil {
+		return nil, false, translateError(err)
+	}
+
+	// Advisory locks belong to a session, so hold one connection until the lock is released.
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, translateError(err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+
+		return nil, false, translateError(err)
+	}
+
+	if !acquired {
+		_ = conn.Close()
+
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			store.logger.Warn("Failed to release advisory lock", zap.Int64("key", key), zap.Error(err))
+		}
+
+		_ = conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// assetScope exclude assets of videos that have expired as of now, unless includeExpired.
+func assetScope(filter AssetFilter, now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !filter.IncludeExpired {
+			db = db.Where(
+				"NOT EXISTS (SELECT 1 FROM videos WHERE videos.id = assets.video_id AND videos.expiration_date <= ?)",
+				now.UTC(),
+			)
+		}
+
+		if filter.AssetType != "" {
+			db = db.Where("assets.asset_type = ?", filter.AssetType)
+		}
+
+		if filter.Name != "" {
+			db = db.Where(`LOWER(assets.name) LIKE ? ESCAPE '\'`, containsPattern(filter.Name))
+		}
+
+		db = timeRangeScope(db, "assets.created_at", filter.CreatedAfter, filter.CreatedBefore)
+
+		return timeRangeScope(db, "assets.updated_at", filter.UpdatedAfter, filter.UpdatedBefore)
+	}
+}
+
+// audited make change in tx, recording an audit event of operation for each row of T matching ids that it altered.
+func audited[T any](ctx context.Context, tx *gorm.DB, operation AuditOperation, ids []uint, change func() error) error {
+	before, err := snapshotRows[T](tx, ids)
+	if err != nil {
+		return err
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+
+	return recordChanges(ctx, tx, operation, ids, before)
+}
+
+// auditedVideoUpdate like audited, for a change updating the video matching videoID, which may move its assets along.
+func auditedVideoUpdate(
+	ctx context.Context,
+	tx *gorm.DB,
+	videoID uint,
+	operation AuditOperation,
+	change func() error,
+) error {
+	// The assets' foreign key moves deleted assets too.
+	assetIDs, err := findIDs(tx.Unscoped().Model(&Asset{}).Where("video_id = ?", videoID))
+	if err != nil {
+		return err
+	}
+
+	return audited[Video](ctx, tx, operation, []uint{videoID}, func() error {
+		return audited[Asset](ctx, tx, AuditUpdate, assetIDs, change)
+	})
+}
+
+// batchError error of a batch transaction, other than the failure of its records, which are reported one by one.
+func batchError(err error) error {
+	if errors.Is(err, errBatchFailed) {
+		return nil
+	}
+
+	return translateError(err)
+}
+
+// batchResult errBatchFailed, rolling back the batch, if its records failed with err, unless continueOnError.
+func batchResult(continueOnError bool, err error) error {
+	if !continueOnError && err != nil {
+		return errBatchFailed
+	}
+
+	return nil
+}
+
+// checkAssetOwners check the container of asset like checkContainer, and then its video like checkAssetVideo.
+func checkAssetOwners(tx *gorm.DB, asset Asset) error {
+	if err := checkContainer(tx, asset.ContainerID); err != nil {
+		return err
+	}
+
+	return checkAssetVideo(tx, asset)
+}
+
+// checkAssetVideo ErrMissingVideo or ErrVideoContainerMismatch unless asset's video, if any, is live and matches.
+func checkAssetVideo(tx *gorm.DB, asset Asset) error {
+	if asset.VideoID == 0 {
+		return nil
+	}
+
+	var video Video
+	if err := tx.Select("id", "container_id").First(&video, asset.VideoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMissingVideo
+		}
+
+		return err
+	}
+
+	if video.ContainerID != asset.ContainerID {
+		return ErrVideoContainerMismatch
+	}
+
+	return nil
+}
+
+// checkContainer ErrMissingContainer if no container matches containerID, and ErrDeletedOwner if it is deleted.
+func checkContainer(tx *gorm.DB, containerID uint) error {
+	var container Container
+
+	err := tx.Unscoped().Select("id", "deleted_at").First(&container, containerID).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrMissingContainer
+	case err != nil:
+		return err
+	case container.DeletedAt.Valid:
+		return ErrDeletedOwner
+	default:
+		return nil
+	}
+}
+
+// containerScope restrict a query on table to containerID, unless it is 0.
+func containerScope(table string, containerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if containerID == 0 {
+			return db
+		}
+
+		return db.Where(table+".container_id = ?", containerID)
+	}
+}
+
+// containsPattern case-insensitive LIKE pattern matching text anywhere, with LIKE wildcards in text escaped.
+func containsPattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(text))
+
+	return "%" + escaped + "%"
+}
+
+// createAsset create asset in tx, checking its video and external ID like CreateAsset.
+func createAsset(ctx context.Context, tx *gorm.DB, asset *Asset) error {
+	err := errors.Join(checkAssetOwners(tx, *asset), checkExternalID(externalIDFinder(tx), "assets", asset.ExternalID))
+	if err != nil {
+		return err
+	}
+
+	// Omitted, video_id is NULL, which stands for no video.
+	create := tx
+	if asset.VideoID == 0 {
+		create = tx.Omit("VideoID")
+	}
+
+	if err := create.Create(asset).Error; err != nil {
+		return err
+	}
+
+	return recordChanges[Asset](ctx, tx, AuditCreate, []uint{asset.ID}, nil)
+}
+
+// createContainer create container in tx, without its assets and videos, checking its external ID.
+func createContainer(ctx context.Context, tx *gorm.DB, container *Container) error {
+	if err := checkExternalID(externalIDFinder(tx), "containers", container.ExternalID); err != nil {
+		return err
+	}
+
+	if err := tx.Omit(clause.Associations).Create(container).Error; err != nil {
+		return err
+	}
+
+	return recordChanges[Container](ctx, tx, AuditCreate, []uint{container.ID}, nil)
+}
+
+// createVideo create video in tx, without its assets, checking its external ID like CreateVideo.
+func createVideo(ctx context.Context, tx *gorm.DB, video *Video) error {
+	err := errors.Join(
+		checkContainer(tx, video.ContainerID),
+		checkExternalID(externalIDFinder(tx), "videos", video.ExternalID),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Omit(clause.Associations).Create(video).Error; err != nil {
+		return err
+	}
+
+	return recordChanges[Video](ctx, tx, AuditCreate, []uint{video.ID}, nil)
+}
+
+// deleteContainer delete the container matching containerID in tx, applying onContents to its videos and assets.
+func deleteContainer(ctx context.Context, tx *gorm.DB, containerID uint, onContents DeletePolicy) error {
+	if err := requireRows(tx.Delete(&Container{}, containerID)); err != nil {
+		return err
+	}
+
+	switch onContents {
+	case Cascade:
+		assetIDs, err := findIDs(tx.Model(&Asset{}).Where("container_id = ?", containerID))
+		if err != nil {
+			return err
+		}
+
+		// The videos and assets share the container's deletion time, as those of a video do, which is how RestoreVideo
+		// and RestoreContainer recognize them.
+		deletedAt := gorm.Expr("(SELECT deleted_at FROM containers WHERE id = ?)", containerID)
+
+		err = audited[Asset](ctx, tx, AuditDelete, assetIDs, func() error {
+			return tx.Model(&Asset{}).Where("container_id = ?", containerID).UpdateColumn("deleted_at", deletedAt).Error
+		})
+		if err != nil {
+			return err
+		}
+
+		videoIDs, err := findIDs(tx.Model(&Video{}).Where("container_id = ?", containerID))
+		if err != nil {
+			return err
+		}
+
+		return audited[Video](ctx, tx, AuditDelete, videoIDs, func() error {
+			return tx.Model(&Video{}).Where("container_id = ?", containerID).UpdateColumn("deleted_at", deletedAt).Error
+		})
+	case Restrict:
+		var videos, assets int64
+		if err := tx.Model(&Video{}).Where("container_id = ?", containerID).Count(&videos).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&Asset{}).Where("container_id = ?", containerID).Count(&assets).Error; err != nil {
+			return err
+		}
+
+		if videos > 0 || assets > 0 {
+			return ErrContainerNotEmpty
+		}
+
+		return nil
+	default:
+		return ErrUnsupportedPolicy
+	}
+}
+
+// deleteVideo delete the video matching videoID in tx, applying onAssets to its assets.
+func deleteVideo(ctx context.Context, tx *gorm.DB, videoID uint, onAssets DeletePolicy) error {
+	if err := requireRows(tx.Delete(&Video{}, videoID)); err != nil {
+		return err
+	}
+
+	assets := tx.Model(&Asset{}).Where("video_id = ?", videoID)
+
+	switch onAssets {
+	case Cascade:
+		assetIDs, err := findIDs(assets)
+		if err != nil {
+			return err
+		}
+
+		// The assets share the video's deletion time, which is how RestoreVideo recognizes them.
+		return audited[Asset](ctx, tx, AuditDelete, assetIDs, func() error {
+			return tx.Model(&Asset{}).
+				Where("video_id = ?", videoID).
+				UpdateColumn("deleted_at", gorm.Expr("(SELECT deleted_at FROM videos WHERE id = ?)", videoID)).
+				Error
+		})
+	case Detach:
+		assetIDs, err := findIDs(assets)
+		if err != nil {
+			return err
+		}
+
+		return audited[Asset](ctx, tx, AuditUpdate, assetIDs, func() error {
+			return tx.Model(&Asset{}).Where("video_id = ?", videoID).Update("video_id", nil).Error
+		})
+	case Restrict:
+		var count int64
+		if err := assets.Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrVideoHasAssets
+		}
+
+		return nil
+	default:
+		return ErrUnsupportedPolicy
+	}
+}
+
+// externalIDFinder find func of catalogOps, looking records up in tx.
+func externalIDFinder(tx *gorm.DB) func(table string, externalID string) (externalRecord, error) {
+	return func(table string, externalID string) (externalRecord, error) {
+		var record externalRecord
+		err := tx.Unscoped().
+			Table(table).
+			Select("id", "version", "deleted_at").
+			Where("external_id = ?", externalID).
+			Take(&record).
+			Error
+
+		return record, translateError(err)
+	}
+}
+
+// findBrokenReferences live rows of table whose column references a missing or deleted row of parentTable.
+func findBrokenReferences(db *gorm.DB, table string, column string, parentTable string) ([]IntegrityIssue, error) {
+	missing, deleted := ContainerMissing, ContainerDeleted
+	if parentTable == "videos" {
+		missing, deleted = VideoMissing, VideoDeleted
+	}
+
+	var issues []IntegrityIssue
+	err := db.Table(table).
+		Select(
+			fmt.Sprintf(
+				"%[1]s.id AS id, CASE WHEN %[3]s.id IS NULL THEN ? ELSE ? END AS problem, %[1]s.%[2]s AS referenced_id",
+				table,
+				column,
+				parentTable,
+			),
+			missing,
+			deleted,
+		).
+		Joins(fmt.Sprintf("LEFT JOIN %[3]s ON %[3]s.id = %[1]s.%[2]s", table, column, parentTable)).
+		Where(
+			fmt.Sprintf(
+				"%[1]s.deleted_at IS NULL AND %[1]s.%[2]s IS NOT NULL AND %[1]s.%[2]s <> 0 AND "+
+					"(%[3]s.id IS NULL OR %[3]s.deleted_at IS NOT NULL)",
+				table,
+				column,
+				parentTable,
+			),
+		).
+		Scan(&issues).
+		Error
+
+	return issues, translateError(err)
+}
+
+// findByIDs rows matching ids, by ID.
+func findByIDs[T any](query *gorm.DB, ids []uint, id func(T) uint) (map[uint]T, error) {
+	var rows []T
+	if err := query.Find(&rows, ids).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	byID := make(map[uint]T, len(rows))
+
+	for _, row := range rows {
+		byID[id(row)] = row
+	}
+
+	return byID, nil
+}
+
+// findIDs IDs of the rows that query matches, in order.
+func findIDs(query *gorm.DB) ([]uint, error) {
+	var ids []uint
+	err := query.Order("id").Pluck("id", &ids).Error
+
+	return ids, err
+}
+
+// purgeRows permanently delete the rows of T that query matches, auditing each, and return how many.
+func purgeRows[T any](ctx context.Context, tx *gorm.DB, query *gorm.DB) (int64, error) {
+	ids, err := findIDs(query.Model(new(T)))
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var purged int64
+	err = audited[T](ctx, tx, AuditPurge, ids, func() error {
+		result := tx.Unscoped().Delete(new(T), ids)
+		purged = result.RowsAffected
+
+		return result.Error
+	})
+
+	return purged, err
+}
+
+// translateError map err to a domain error, wrapping errors that are not one as internal errors.
+func translateError(err error) error {
+	var appErr *apperr.Error
+
+	switch {
+	case err == nil || errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperr.Wrap(apperr.Conflict, err, "record already exists")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperr.Wrap(apperr.Conflict, err, "record references a missing record, or is still referenced")
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return apperr.Wrap(apperr.Validation, err, "value not allowed")
+	default:
+		return apperr.Wrap(apperr.Internal, err, "database error")
+	}
+}
+
+// recordChanges record an audit event for each row of T matching ids that differs from before, and video revisions.
+func recordChanges[T any](
+	ctx context.Context,
+	tx *gorm.DB,
+	operation AuditOperation,
+	ids []uint,
+	before map[uint]T,
+) error {
+	after, err := snapshotRows[T](tx, ids)
+	if err != nil {
+		return err
+	}
+
+	var events []AuditEvent
+	var revisions []VideoRevision
+
+	for _, id := range ids {
+		var beforeRow, afterRow interface{}
+		if row, ok := before[id]; ok {
+			beforeRow = row
+		}
+
+		if row, ok := after[id]; ok {
+			afterRow = row
+		}
+
+		event, changed, err := newAuditEvent(ctx, operation, beforeRow, afterRow)
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			continue
+		}
+
+		events = append(events, event)
+
+		if video, ok := afterRow.(Video); ok {
+			revisions = append(revisions, newVideoRevision(event, video))
+		}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&events).Error; err != nil {
+		return err
+	}
+
+	// The unique index on the revision numbers fails one of two concurrent changes to a video.
+	for _, revision := range revisions {
+		err := tx.Model(&VideoRevision{}).
+			Select("COALESCE(MAX(revision), 0) + 1").
+			Where("video_id = ?", revision.VideoID).
+			Scan(&revision.Revision).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requireRows error of result, or ErrNotFound if it affected no rows.
+func requireRows(result *gorm.DB) error {
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return translateError(result.Error)
+}
+
+// requireLiveOwners ErrDeletedOwner unless the container matching containerID, and its video videoID if any, are live.
+func requireLiveOwners(tx *gorm.DB, containerID uint, videoID uint) error {
+	var containers, videos int64
+	if err := tx.Model(&Container{}).Where("id = ?", containerID).Count(&containers).Error; err != nil {
+		return err
+	}
+
+	if videoID != 0 {
+		if err := tx.Model(&Video{}).Where("id = ?", videoID).Count(&videos).Error; err != nil {
+			return err
+		}
+	}
+
+	if containers == 0 || (videoID != 0 && videos == 0) {
+		return ErrDeletedOwner
+	}
+
+	return nil
+}
+
+// restoreContents restore the videos and assets deleted along with the container matching containerID in tx.
+func restoreContents(ctx context.Context, tx *gorm.DB, containerID uint) error {
+	deletedWith := "container_id = ? AND deleted_at = (SELECT deleted_at FROM containers WHERE id = ?)"
+
+	videoIDs, err := findIDs(tx.Unscoped().Model(&Video{}).Where(deletedWith, containerID, containerID))
+	if err != nil {
+		return err
+	}
+
+	err = audited[Video](ctx, tx, AuditRestore, videoIDs, func() error {
+		return tx.Unscoped().Model(&Video{}).Where("id IN ?", videoIDs).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	assets := tx.Unscoped().
+		Model(&Asset{}).
+		Where(deletedWith, containerID, containerID).
+		Where("video_id IS NULL OR video_id IN (SELECT id FROM videos WHERE deleted_at IS NULL)")
+
+	assetIDs, err := findIDs(assets)
+	if err != nil {
+		return err
+	}
+
+	return audited[Asset](ctx, tx, AuditRestore, assetIDs, func() error {
+		return tx.Unscoped().Model(&Asset{}).Where("id IN ?", assetIDs).Update("deleted_at", nil).Error
+	})
+}
+
+// savepointStep batchStep running each creation in a savepoint of tx, which a failure rolls back to.
+func savepointStep(tx *gorm.DB) batchStep {
+	return func(create func() error) error {
+		if err := tx.SavePoint(batchSavepoint).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := create(); err != nil {
+			return errors.Join(err, translateError(tx.RollbackTo(batchSavepoint).Error))
+		}
+
+		return translateError(tx.Exec("RELEASE SAVEPOINT " + batchSavepoint).Error)
+	}
+}
+
+// searchFullText search videos and assets using Postgres full-text search.
+func (store *gormStore) searchFullText(
+	ctx context.Context,
+	query string,
+	filter SearchFilter,
+	limit int,
+) ([]SearchResult, error) {
+	type match struct {
+		ID      uint
+		Score   float64
+		Snippet string
+	}
+
+	now := time.Now()
+	db := store.db.WithContext(ctx)
+
+	var videoMatches []match
+	err := db.Model(&Video{}).
+		Select(
+			"videos.id, ts_rank(videos.search_vector, search_query) AS score, "+
+				"ts_headline('english', "+htmlEscapeSQL("videos.title || ' ' || videos.description")+
+				", search_query, ?) AS snippet",
+			headlineOptions,
+		).
+		Joins("CROSS JOIN plainto_tsquery('english', ?) AS search_query", query).
+		Scopes(
+			videoScope(VideoFilter{IncludeExpired: filter.IncludeExpired}, now),
+			containerScope("videos", filter.ContainerID),
+		).
+		Where("videos.search_vector @@ search_query").
+		Order("score DESC, videos.id").
+		Limit(limit).
+		Scan(&videoMatches).
+		Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var assetMatches []match
+	err = db.Model(&Asset{}).
+		Select(
+			"assets.id, ts_rank(assets.search_vector, search_query) AS score, "+
+				"ts_headline('english', "+htmlEscapeSQL("assets.name")+", search_query, ?) AS snippet",
+			headlineOptions,
+		).
+		Joins("CROSS JOIN plainto_tsquery('english', ?) AS search_query", query).
+		Scopes(
+			assetScope(AssetFilter{IncludeExpired: filter.IncludeExpired}, now),
+			containerScope("assets", filter.ContainerID),
+		).
+		Where("assets.search_vector @@ search_query").
+		Order("score DESC, assets.id").
+		Limit(limit).
+		Scan(&assetMatches).
+		Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	videoIDs := make([]uint, 0, len(videoMatches))
+	for _, videoMatch := range videoMatches {
+		videoIDs = append(videoIDs, videoMatch.ID)
+	}
+
+	assetIDs := make([]uint, 0, len(assetMatches))
+	for _, assetMatch := range assetMatches {
+		assetIDs = append(assetIDs, assetMatch.ID)
+	}
+
+	videos := make(map[uint]Video, len(videoIDs))
+	assets := make(map[uint]Asset, len(assetIDs))
+
+	if len(videoIDs) > 0 {
+		var rows []Video
+		if err := db.Find(&rows, videoIDs).Error; err != nil {
+			return nil, translateError(err)
+		}
+
+		for _, row := range rows {
+			videos[row.ID] = row
+		}
+	}
+
+	if len(assetIDs) > 0 {
+		var rows []Asset
+		if err := db.Find(&rows, assetIDs).Error; err != nil {
+			return nil, translateError(err)
+		}
+
+		for _, row := range rows {
+			assets[row.ID] = row
+		}
+	}
+
+	results := make([]SearchResult, 0, len(videoMatches)+len(assetMatches))
+
+	for _, videoMatch := range videoMatches {
+		// Skip rows deleted between the two queries.
+		if video, ok := videos[videoMatch.ID]; ok {
+			results = append(results, SearchResult{Score: videoMatch.Score, Snippet: videoMatch.Snippet, Video: &video})
+		}
+	}
+
+	for _, assetMatch := range assetMatches {
+		if asset, ok := assets[assetMatch.ID]; ok {
+			results = append(results, SearchResult{Asset: &asset, Score: assetMatch.Score, Snippet: assetMatch.Snippet})
+		}
+	}
+
+	return sortSearchResults(results, limit), nil
+}
+
+// snapshotRows rows of T matching ids, deleted or not, by ID.
+func snapshotRows[T any](tx *gorm.DB, ids []uint) (map[uint]T, error) {
+	rows := make(map[uint]T, len(ids))
+	if len(ids) == 0 {
+		return rows, nil
+	}
+
+	var found []T
+	if err := tx.Unscoped().Find(&found, ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range found {
+		_, id := auditedRecord(row)
+		rows[id] = row
+	}
+
+	return rows, nil
+}
+
+// timeRangeScope restrict db to rows whose column is within the inclusive range from after to before, if set.
+func timeRangeScope(db *gorm.DB, column string, after *time.Time, before *time.Time) *gorm.DB {
+	if after != nil {
+		db = db.Where(column+" >= ?", after.UTC())
+	}
+
+	if before != nil {
+		db = db.Where(column+" <= ?", before.UTC())
+	}
+
+	return db
+}
+
+// updateAsset update asset in tx, checking its video like CreateAsset, then reload it.
+func updateAsset(ctx context.Context, tx *gorm.DB, asset *Asset) error {
+	// Unlike Save, Updates never inserts a row when the asset does not exist.
+	return audited[Asset](ctx, tx, AuditUpdate, []uint{asset.ID}, func() error {
+		err := updateVersionedRow(tx, asset, asset.ID, asset.Version, map[string]interface{}{
+			"asset_type":   asset.AssetType,
+			"container_id": asset.ContainerID,
+			"name":         asset.Name,
+			"url":          asset.URL,
+			"video_id":     nullableID(asset.VideoID),
+		})
+		if err != nil {
+			return err
+		}
+
+		return checkAssetOwners(tx, *asset)
+	})
+}
+
+// updateContainer update the name and description of container in tx, then reload it.
+func updateContainer(ctx context.Context, tx *gorm.DB, container *Container) error {
+	return audited[Container](ctx, tx, AuditUpdate, []uint{container.ID}, func() error {
+		return updateVersionedRow(tx, container, container.ID, container.Version, map[string]interface{}{
+			"description": container.Description,
+			"name":        container.Name,
+		})
+	})
+}
+
+// updateRow update columns of the row of row's table matching id, unless empty, then read the row back into row.
+func updateRow[T any](tx *gorm.DB, row *T, id uint, columns map[string]interface{}) error {
+	if len(columns) > 0 {
+		if err := requireRows(tx.Model(new(T)).Where("id = ?", id).Updates(columns)); err != nil {
+			return err
+		}
+	}
+
+	// Reset first, as scanning NULL leaves a field as it was.
+	*row = *new(T)
+
+	return translateError(tx.First(row, id).Error)
+}
+
+// updateVersionedRow like updateRow, but only if the row is still at version. Returns ErrVersionConflict if it is not.
+func updateVersionedRow[T any](tx *gorm.DB, row *T, id uint, version uint, columns map[string]interface{}) error {
+	updated := false
+
+	// Checking the version in the update itself keeps a concurrent change from slipping in between.
+	if len(columns) > 0 {
+		result := tx.Model(new(T)).Where("id = ? AND version = ?", id, version).Updates(columns)
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+
+		updated = result.RowsAffected > 0
+	}
+
+	if !updated {
+		var versions []uint
+		if err := tx.Model(new(T)).Where("id = ?", id).Pluck("version", &versions).Error; err != nil {
+			return translateError(err)
+		}
+
+		if len(versions) == 0 {
+			return ErrNotFound
+		}
+
+		if versions[0] != version {
+			return ErrVersionConflict
+		}
+	}
+
+	return updateRow(tx, row, id, nil)
+}
+
+// updateVideo update video in tx, moving its assets along, then reload it.
+func updateVideo(ctx context.Context, tx *gorm.DB, video *Video) error {
+	// Unlike Save, Updates never inserts a row when the video does not exist. Expiry bookkeeping belongs to the
+	// sweeper, not to editors.
+	return auditedVideoUpdate(ctx, tx, video.ID, AuditUpdate, func() error {
+		err := updateVersionedRow(tx, video, video.ID, video.Version, map[string]interface{}{
+			"container_id":    video.ContainerID,
+			"description":     video.Description,
+			"expiration_date": video.ExpirationDate,
+			"playback_url":    video.PlaybackURL,
+			"title":           video.Title,
+			"video_type":      video.VideoType,
+		})
+		if err != nil {
+			return err
+		}
+
+		return checkContainer(tx, video.ContainerID)
+	})
+}
+
+// videoScope restrict a video query to filter as of now.
+func videoScope(filter VideoFilter, now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !filter.IncludeExpired {
+			db = db.Where("(videos.expiration_date IS NULL OR videos.expiration_date > ?)", now.UTC())
+		}
+
+		if filter.Title != "" {
+			db = db.Where(`LOWER(videos.title) LIKE ? ESCAPE '\'`, containsPattern(filter.Title))
+		}
+
+		if filter.VideoType != "" {
+			db = db.Where("videos.video_type = ?", filter.VideoType)
+		}
+
+		db = timeRangeScope(db, "videos.created_at", filter.CreatedAfter, filter.CreatedBefore)
+		db = timeRangeScope(db, "videos.expiration_date", filter.ExpiresAfter, filter.ExpiresBefore)
+
+		return timeRangeScope(db, "videos.updated_at", filter.UpdatedAfter, filter.UpdatedBefore)
+	}
+}
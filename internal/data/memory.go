@@ -0,0 +1,1614 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryCheckpoint state of a memoryStore that restore rolls back to.
+type memoryCheckpoint struct {
+	auditEvents []AuditEvent
+	undo        int
+}
+
+// memoryStore Store backed by in-process maps, for running without a database.
+type memoryStore struct {
+	assets      map[uint]Asset
+	auditEvents []AuditEvent
+	// checkpoints number of checkpoints not yet released, while which writes are logged to undoLog.
+	checkpoints int
+	containers  map[uint]Container
+	locks       localLocks
+	logger      *zap.Logger
+	// mutex lock guarding the other fields, which the callers of private methods must hold.
+	mutex     sync.RWMutex
+	nextID    map[string]uint
+	revisions map[uint][]VideoRevision
+	// undoLog how to undo each write since the first checkpoint not yet released, in order.
+	undoLog []func()
+	videos  map[uint]Video
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// NewMemoryStore create an empty in-memory Store.
+func NewMemoryStore(logger *zap.Logger) Store {
+	return &memoryStore{
+		assets:     make(map[uint]Asset),
+		containers: make(map[uint]Container),
+		logger:     logger,
+		nextID:     make(map[string]uint),
+		revisions:  make(map[uint][]VideoRevision),
+		videos:     make(map[uint]Video),
+	}
+}
+
+/* ****************************************************** Asset ***************************************************** */
+
+// CreateAsset create the asset in memory.
+func (store *memoryStore) CreateAsset(ctx context.Context, asset *Asset) error {
+	store.logger.Debug(
+		"Creating asset",
+		zap.String("assetType", string(asset.AssetType)),
+		zap.Uint("containerID", asset.ContainerID),
+		zap.String("name", asset.Name),
+		zap.String("url", asset.URL),
+		zap.Uint("videoID", asset.VideoID),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.createAsset(ctx, asset)
+}
+
+// CreateAssets create the assets in memory, undoing every creation if one fails unless continueOnError.
+func (store *memoryStore) CreateAssets(ctx context.Context, assets []Asset, continueOnError bool) ([]error, error) {
+	store.logger.Debug("Creating assets", zap.Int("count", len(assets)), zap.Bool("continueOnError", continueOnError))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	saved := store.checkpoint()
+	defer store.release()
+	errs := createEach(len(assets), continueOnError, store.atomically, func(i int) error {
+		return store.createAsset(ctx, &assets[i])
+	})
+
+	if !continueOnError && errors.Join(errs...) != nil {
+		store.restore(saved)
+	}
+
+	return errs, nil
+}
+
+// DeleteAsset delete the asset matching assetID from memory.
+func (store *memoryStore) DeleteAsset(ctx context.Context, assetID uint) error {
+	store.logger.Debug("Deleting asset", zap.Uint("assetID", assetID))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	asset, ok := store.assets[assetID]
+	if !ok || asset.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	asset.DeletedAt = deletedAt(time.Now())
+
+	return store.put(ctx, AuditDelete, &asset)
+}
+
+// GetAssets get a page of assets matching containerID and filter.
+func (store *memoryStore) GetAssets(
+	ctx context.Context,
+	containerID uint,
+	filter AssetFilter,
+	order Order,
+	page Page,
+) (Connection[Asset], error) {
+	store.logger.Debug(
+		"Getting assets",
+		zap.Uint("containerID", containerID),
+		zap.Any("filter", filter),
+		zap.Any("order", order),
+	)
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	now := time.Now()
+
+	assets := store.findAssets(func(asset Asset) bool {
+		return asset.ContainerID == containerID &&
+			matchesAssetFilter(asset, filter) &&
+			(filter.IncludeExpired || !store.videoExpired(asset.VideoID, now))
+	})
+
+	return paginateSlice(assets, assetKeyset, page, order)
+}
+
+// GetAssetsByIDs get the live assets matching assetIDs from memory.
+func (store *memoryStore) GetAssetsByIDs(ctx context.Context, assetIDs []uint) (map[uint]Asset, error) {
+	store.logger.Debug("Getting assets by IDs", zap.Uints("assetIDs", assetIDs))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	assets := make(map[uint]Asset, len(assetIDs))
+
+	for _, assetID := range assetIDs {
+		if asset, ok := store.assets[assetID]; ok && !asset.DeletedAt.Valid {
+			assets[assetID] = asset
+		}
+	}
+
+	return assets, nil
+}
+
+// GetAssetsByVideos get the live assets of each video matching videoIDs from memory.
+func (store *memoryStore) GetAssetsByVideos(ctx context.Context, videoIDs []uint) (map[uint][]Asset, error) {
+	store.logger.Debug("Getting assets by videos", zap.Uints("videoIDs", videoIDs))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	wanted := make(map[uint]bool, len(videoIDs))
+
+	for _, videoID := range videoIDs {
+		wanted[videoID] = true
+	}
+
+	byVideo := make(map[uint][]Asset, len(videoIDs))
+
+	for _, asset := range store.findAssets(func(asset Asset) bool { return wanted[asset.VideoID] }) {
+		byVideo[asset.VideoID] = append(byVideo[asset.VideoID], asset)
+	}
+
+	return byVideo, nil
+}
+
+// GetDeletedAssets get a page of deleted assets matching containerID from memory.
+func (store *memoryStore) GetDeletedAssets(
+	ctx context.Context,
+	containerID uint,
+	page Page,
+) (Connection[Asset], error) {
+	store.logger.Debug("Getting deleted assets", zap.Uint("containerID", containerID))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	assets := make([]Asset, 0, 16)
+
+	for _, asset := range store.assets {
+		if asset.DeletedAt.Valid && (containerID == 0 || asset.ContainerID == containerID) {
+			assets = append(assets, asset)
+		}
+	}
+
+	sort.Slice(assets, func(i, j int) bool { return assets[i].ID < assets[j].ID })
+
+	return paginateSlice(assets, assetKeyset, page, Order{})
+}
+
+// PatchAsset apply patch to the asset matching assetID in memory.
+func (store *memoryStore) PatchAsset(ctx context.Context, assetID uint, patch AssetPatch) (Asset, error) {
+	store.logger.Debug("Patching asset", zap.Uint("assetID", assetID), zap.Any("patch", patch))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	asset, ok := store.assets[assetID]
+	if !ok || asset.DeletedAt.Valid {
+		return Asset{}, ErrNotFound
+	}
+
+	if asset.Version != patch.Version {
+		return Asset{}, ErrVersionConflict
+	}
+
+	if len(patch.columns()) > 0 {
+		patch.apply(&asset)
+
+		if err := store.checkAssetOwners(asset); err != nil {
+			return Asset{}, err
+		}
+
+		asset.UpdatedAt = time.Now()
+
+		if err := store.put(ctx, AuditUpdate, &asset); err != nil {
+			return Asset{}, err
+		}
+	}
+
+	return asset, nil
+}
+
+// RestoreAsset restore the deleted asset matching assetID in memory.
+func (store *memoryStore) RestoreAsset(ctx context.Context, assetID uint) (Asset, error) {
+	store.logger.Debug("Restoring asset", zap.Uint("assetID", assetID))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	asset, ok := store.assets[assetID]
+	if !ok || !asset.DeletedAt.Valid {
+		return Asset{}, ErrNotFound
+	}
+
+	if err := store.requireLiveOwners(asset.ContainerID, asset.VideoID); err != nil {
+		return Asset{}, err
+	}
+
+	asset.DeletedAt = gorm.DeletedAt{}
+	asset.UpdatedAt = time.Now()
+
+	if err := store.put(ctx, AuditRestore, &asset); err != nil {
+		return Asset{}, err
+	}
+
+	return asset, nil
+}
+
+// UpdateAsset update the asset in memory.
+func (store *memoryStore) UpdateAsset(ctx context.Context, asset *Asset) error {
+	store.logger.Debug(
+		"Updating asset",
+		zap.String("assetType", string(asset.AssetType)),
+		zap.Uint("containerID", asset.ContainerID),
+		zap.Uint("id", asset.ID),
+		zap.String("name", asset.Name),
+		zap.String("url", asset.URL),
+		zap.Uint("videoID", asset.VideoID),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.updateAsset(ctx, asset)
+}
+
+/* *************************************************** Container **************************************************** */
+
+// CreateContainer create the container in memory.
+func (store *memoryStore) CreateContainer(ctx context.Context, container *Container) error {
+	store.logger.Debug(
+		"Creating container",
+		zap.String("description", container.Description),
+		zap.String("name", container.Name),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.createContainer(ctx, container)
+}
+
+// CreateContainerWithContent create the container and its content in memory.
+func (store *memoryStore) CreateContainerWithContent(
+	ctx context.Context,
+	container *Container,
+	continueOnError bool,
+) (ContentErrors, error) {
+	store.logger.Debug(
+		"Creating container with content",
+		zap.String("name", container.Name),
+		zap.Int("videos", len(container.Videos)),
+		zap.Int("assets", len(container.Assets)),
+		zap.Bool("continueOnError", continueOnError),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	saved := store.checkpoint()
+	defer store.release()
+	if err := store.createContainer(ctx, container); err != nil {
+		return ContentErrors{Container: err}, nil
+	}
+
+	errs := createContent(
+		container,
+		continueOnError,
+		store.atomically,
+		func(video *Video) error { return store.createVideo(ctx, video) },
+		func(asset *Asset) error { return store.createAsset(ctx, asset) },
+	)
+
+	if !continueOnError && errs.join() != nil {
+		store.restore(saved)
+	}
+
+	return errs, nil
+}
+
+// DeleteContainer delete the container matching containerID from memory, along with its contents per onContents.
+func (store *memoryStore) DeleteContainer(ctx context.Context, containerID uint, onContents DeletePolicy) error {
+	store.logger.Debug(
+		"Deleting container",
+		zap.Uint("containerID", containerID),
+		zap.String("onContents", string(onContents)),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	container, ok := store.containers[containerID]
+	if !ok || container.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	assets := store.findAssets(func(asset Asset) bool { return asset.ContainerID == containerID })
+	videos := store.findVideos(func(video Video) bool { return video.ContainerID == containerID })
+	now := deletedAt(time.Now())
+
+	switch onContents {
+	case Cascade:
+	case Restrict:
+		if len(assets) > 0 || len(videos) > 0 {
+			return ErrContainerNotEmpty
+		}
+	default:
+		return ErrUnsupportedPolicy
+	}
+
+	return store.atomically(func() error {
+		for _, asset := range assets {
+			asset.DeletedAt = now
+
+			if err := store.put(ctx, AuditDelete, &asset); err != nil {
+				return err
+			}
+		}
+
+		for _, video := range videos {
+			video.DeletedAt = now
+
+			if err := store.put(ctx, AuditDelete, &video); err != nil {
+				return err
+			}
+		}
+
+		container.DeletedAt = now
+
+		return store.put(ctx, AuditDelete, &container)
+	})
+}
+
+// GetContainer get the container matching containerID.
+func (store *memoryStore) GetContainer(ctx context.Context, containerID uint) (Container, error) {
+	store.logger.Debug("Getting container", zap.Uint("containerID", containerID))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	container, ok := store.containers[containerID]
+	if !ok || container.DeletedAt.Valid {
+		return Container{}, ErrNotFound
+	}
+
+	return container, nil
+}
+
+// GetContainers get all containers.
+func (store *memoryStore) GetContainers(ctx context.Context, page Page) (Connection[Container], error) {
+	store.logger.Debug("Getting containers")
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	containers := make([]Container, 0, len(store.containers))
+
+	for _, container := range store.containers {
+		if !container.DeletedAt.Valid {
+			containers = append(containers, container)
+		}
+	}
+
+	return paginateSlice(containers, containerKeyset, page, Order{})
+}
+
+// GetContainersByIDs get the live containers matching containerIDs from memory.
+func (store *memoryStore) GetContainersByIDs(ctx context.Context, containerIDs []uint) (map[uint]Container, error) {
+	store.logger.Debug("Getting containers by IDs", zap.Uints("containerIDs", containerIDs))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	containers := make(map[uint]Container, len(containerIDs))
+
+	for _, containerID := range containerIDs {
+		if container, ok := store.containers[containerID]; ok && !container.DeletedAt.Valid {
+			containers[containerID] = container
+		}
+	}
+
+	return containers, nil
+}
+
+// GetDeletedContainers get a page of deleted containers from memory.
+func (store *memoryStore) GetDeletedContainers(ctx context.Context, page Page) (Connection[Container], error) {
+	store.logger.Debug("Getting deleted containers")
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	containers := make([]Container, 0, 16)
+
+	for _, container := range store.containers {
+		if container.DeletedAt.Valid {
+			containers = append(containers, container)
+		}
+	}
+
+	sort.Slice(containers, func(i, j int) bool { return containers[i].ID < containers[j].ID })
+
+	return paginateSlice(containers, containerKeyset, page, Order{})
+}
+
+// RestoreContainer restore the deleted container matching containerID in memory.
+func (store *memoryStore) RestoreContainer(
+	ctx context.Context,
+	containerID uint,
+	withContents bool,
+) (Container, error) {
+	store.logger.Debug(
+		"Restoring container",
+		zap.Uint("containerID", containerID),
+		zap.Bool("withContents", withContents),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	container, ok := store.containers[containerID]
+	if !ok || !container.DeletedAt.Valid {
+		return Container{}, ErrNotFound
+	}
+
+	now := time.Now()
+
+	if withContents {
+		if err := store.restoreContents(ctx, container, now); err != nil {
+			return Container{}, err
+		}
+	}
+
+	container.DeletedAt = gorm.DeletedAt{}
+	container.UpdatedAt = now
+
+	if err := store.put(ctx, AuditRestore, &container); err != nil {
+		return Container{}, err
+	}
+
+	return container, nil
+}
+
+// UpdateContainer update the container in memory.
+func (store *memoryStore) UpdateContainer(ctx context.Context, container *Container) error {
+	store.logger.Debug(
+		"Updating container",
+		zap.String("description", container.Description),
+		zap.Uint("id", container.ID),
+		zap.String("name", container.Name),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.updateContainer(ctx, container)
+}
+
+/* ***************************************************** Video ****************************************************** */
+
+// CreateVideo create the video in memory.
+func (store *memoryStore) CreateVideo(ctx context.Context, video *Video) error {
+	store.logger.Debug(
+		"Creating video",
+		zap.Uint("containerID", video.ContainerID),
+		zap.String("description", video.Description),
+		zap.Timep("expirationDate", video.ExpirationDate),
+		zap.String("playbackUrl", video.PlaybackURL),
+		zap.String("title", video.Title),
+		zap.String("videoType", string(video.VideoType)),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.createVideo(ctx, video)
+}
+
+// CreateVideos create the videos in memory, undoing every creation if one fails unless continueOnError.
+func (store *memoryStore) CreateVideos(ctx context.Context, videos []Video, continueOnError bool) ([]error, error) {
+	store.logger.Debug("Creating videos", zap.Int("count", len(videos)), zap.Bool("continueOnError", continueOnError))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	saved := store.checkpoint()
+	defer store.release()
+	errs := createEach(len(videos), continueOnError, store.atomically, func(i int) error {
+		return store.createVideo(ctx, &videos[i])
+	})
+
+	if !continueOnError && errors.Join(errs...) != nil {
+		store.restore(saved)
+	}
+
+	return errs, nil
+}
+
+// DeleteVideo delete the video matching videoID from memory, applying onAssets to its assets.
+func (store *memoryStore) DeleteVideo(ctx context.Context, videoID uint, onAssets DeletePolicy) error {
+	store.logger.Debug(
+		"Deleting video",
+		zap.Uint("videoID", videoID),
+		zap.String("onAssets", string(onAssets)),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	video, ok := store.videos[videoID]
+	if !ok || video.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	assets := store.findAssets(func(asset Asset) bool { return asset.VideoID == videoID })
+	now := time.Now()
+
+	switch onAssets {
+	case Cascade:
+		for i := range assets {
+			assets[i].DeletedAt = deletedAt(now)
+		}
+	case Detach:
+		for i := range assets {
+			assets[i].UpdatedAt = now
+			assets[i].VideoID = 0
+		}
+	case Restrict:
+		if len(assets) > 0 {
+			return ErrVideoHasAssets
+		}
+	default:
+		return ErrUnsupportedPolicy
+	}
+
+	return store.atomically(func() error {
+		for _, asset := range assets {
+			operation := AuditUpdate
+			if asset.DeletedAt.Valid {
+				operation = AuditDelete
+			}
+
+			if err := store.put(ctx, operation, &asset); err != nil {
+				return err
+			}
+		}
+
+		video.DeletedAt = deletedAt(now)
+
+		return store.put(ctx, AuditDelete, &video)
+	})
+}
+
+// GetDeletedVideos get a page of deleted videos matching containerID from memory.
+func (store *memoryStore) GetDeletedVideos(
+	ctx context.Context,
+	containerID uint,
+	page Page,
+) (Connection[Video], error) {
+	store.logger.Debug("Getting deleted videos", zap.Uint("containerID", containerID))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	videos := make([]Video, 0, 16)
+
+	for _, video := range store.videos {
+		if video.DeletedAt.Valid && (containerID == 0 || video.ContainerID == containerID) {
+			videos = append(videos, video)
+		}
+	}
+
+	sort.Slice(videos, func(i, j int) bool { return videos[i].ID < videos[j].ID })
+
+	return paginateSlice(videos, videoKeyset, page, Order{})
+}
+
+// GetVideoRevision get revision number revision of the video matching videoID from memory.
+func (store *memoryStore) GetVideoRevision(ctx context.Context, videoID uint, revision uint) (VideoRevision, error) {
+	store.logger.Debug("Getting video revision", zap.Uint("videoID", videoID), zap.Uint("revision", revision))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.findRevision(videoID, revision)
+}
+
+// GetVideoRevisions get a page of the revisions of the video matching videoID from memory.
+func (store *memoryStore) GetVideoRevisions(
+	ctx context.Context,
+	videoID uint,
+	page Page,
+) (Connection[VideoRevision], error) {
+	store.logger.Debug("Getting video revisions", zap.Uint("videoID", videoID))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return paginateSlice(store.revisions[videoID], videoRevisionKeyset, page, Order{})
+}
+
+// GetVideosByContainer get a page of videos matching containerID and filter.
+func (store *memoryStore) GetVideosByContainer(
+	ctx context.Context,
+	containerID uint,
+	filter VideoFilter,
+	order Order,
+	page Page,
+) (Connection[Video], error) {
+	store.logger.Debug(
+		"Getting videos",
+		zap.Uint("containerID", containerID),
+		zap.Any("filter", filter),
+		zap.Any("order", order),
+	)
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	now := time.Now()
+
+	videos := store.findVideos(func(video Video) bool {
+		return video.ContainerID == containerID && matchesVideoFilter(video, filter, now)
+	})
+
+	return paginateSlice(videos, videoKeyset, page, order)
+}
+
+// GetVideosByIDs get the live videos matching videoIDs from memory.
+func (store *memoryStore) GetVideosByIDs(ctx context.Context, videoIDs []uint) (map[uint]Video, error) {
+	store.logger.Debug("Getting videos by IDs", zap.Uints("videoIDs", videoIDs))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	videos := make(map[uint]Video, len(videoIDs))
+
+	for _, videoID := range videoIDs {
+		if video, ok := store.videos[videoID]; ok && !video.DeletedAt.Valid {
+			videos[videoID] = video
+		}
+	}
+
+	return videos, nil
+}
+
+// PatchVideo apply patch to the video matching videoID in memory.
+func (store *memoryStore) PatchVideo(ctx context.Context, videoID uint, patch VideoPatch) (Video, error) {
+	store.logger.Debug("Patching video", zap.Uint("videoID", videoID), zap.Any("patch", patch))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	video, ok := store.videos[videoID]
+	if !ok || video.DeletedAt.Valid {
+		return Video{}, ErrNotFound
+	}
+
+	if video.Version != patch.Version {
+		return Video{}, ErrVersionConflict
+	}
+
+	if len(patch.columns()) > 0 {
+		patch.apply(&video)
+		video.UpdatedAt = time.Now()
+
+		if err := store.checkContainer(video.ContainerID); err != nil {
+			return Video{}, err
+		}
+
+		err := store.atomically(func() error {
+			if err := store.moveAssets(ctx, videoID, video.ContainerID); err != nil {
+				return err
+			}
+
+			return store.put(ctx, AuditUpdate, &video)
+		})
+		if err != nil {
+			return Video{}, err
+		}
+	}
+
+	return video, nil
+}
+
+// RestoreVideo restore the deleted video matching videoID in memory.
+func (store *memoryStore) RestoreVideo(ctx context.Context, videoID uint, withAssets bool) (Video, error) {
+	store.logger.Debug("Restoring video", zap.Uint("videoID", videoID), zap.Bool("withAssets", withAssets))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	video, ok := store.videos[videoID]
+	if !ok || !video.DeletedAt.Valid {
+		return Video{}, ErrNotFound
+	}
+
+	if err := store.requireLiveOwners(video.ContainerID, 0); err != nil {
+		return Video{}, err
+	}
+
+	now := time.Now()
+
+	if withAssets {
+		for _, assetID := range sortedIDs(store.assets) {
+			asset := store.assets[assetID]
+			if asset.VideoID != videoID || !asset.DeletedAt.Valid || !asset.DeletedAt.Time.Equal(video.DeletedAt.Time) {
+				continue
+			}
+
+			asset.DeletedAt = gorm.DeletedAt{}
+			asset.UpdatedAt = now
+
+			if err := store.put(ctx, AuditRestore, &asset); err != nil {
+				return Video{}, err
+			}
+		}
+	}
+
+	video.DeletedAt = gorm.DeletedAt{}
+	video.UpdatedAt = now
+
+	if err := store.put(ctx, AuditRestore, &video); err != nil {
+		return Video{}, err
+	}
+
+	return video, nil
+}
+
+// RevertVideo revert the video matching videoID in memory to its revision number revision.
+func (store *memoryStore) RevertVideo(ctx context.Context, videoID uint, revision uint) (Video, error) {
+	store.logger.Debug("Reverting video", zap.Uint("videoID", videoID), zap.Uint("revision", revision))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	target, err := store.findRevision(videoID, revision)
+	if err != nil {
+		return Video{}, err
+	}
+
+	if err := store.requireLiveOwners(target.ContainerID, 0); err != nil {
+		return Video{}, err
+	}
+
+	video, ok := store.videos[videoID]
+	if !ok || video.DeletedAt.Valid {
+		return Video{}, ErrNotFound
+	}
+
+	target.apply(&video)
+	video.UpdatedAt = time.Now()
+
+	if err := store.moveAssets(ctx, videoID, video.ContainerID); err != nil {
+		return Video{}, err
+	}
+
+	if err := store.put(ctx, AuditRevert, &video); err != nil {
+		return Video{}, err
+	}
+
+	return video, nil
+}
+
+// UpdateVideo update the video in memory.
+func (store *memoryStore) UpdateVideo(ctx context.Context, video *Video) error {
+	store.logger.Debug(
+		"Updating video",
+		zap.Uint("containerID", video.ContainerID),
+		zap.Uint("id", video.ID),
+		zap.String("description", video.Description),
+		zap.Timep("expirationDate", video.ExpirationDate),
+		zap.String("playbackUrl", video.PlaybackURL),
+		zap.String("title", video.Title),
+		zap.String("videoType", string(video.VideoType)),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.updateVideo(ctx, video)
+}
+
+/* ***************************************************** Search ***************************************************** */
+
+// Search search live videos and assets in memory.
+func (store *memoryStore) Search(
+	ctx context.Context,
+	query string,
+	filter SearchFilter,
+	limit int,
+) ([]SearchResult, error) {
+	store.logger.Debug("Searching", zap.String("query", query), zap.Any("filter", filter), zap.Int("limit", limit))
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	now := time.Now()
+
+	videos := store.findVideos(func(video Video) bool {
+		return (filter.ContainerID == 0 || video.ContainerID == filter.ContainerID) &&
+			(filter.IncludeExpired || !video.Expired(now))
+	})
+	assets := store.findAssets(func(asset Asset) bool {
+		return (filter.ContainerID == 0 || asset.ContainerID == filter.ContainerID) &&
+			(filter.IncludeExpired || !store.videoExpired(asset.VideoID, now))
+	})
+
+	return rankSearchResults(terms, videos, assets, limit), nil
+}
+
+/* *************************************************** Integrity **************************************************** */
+
+// IntegrityReport find the live rows in memory whose references are broken.
+func (store *memoryStore) IntegrityReport(ctx context.Context) (IntegrityReport, error) {
+	store.logger.Debug("Reporting integrity")
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var report IntegrityReport
+
+	for _, asset := range store.findAssets(func(Asset) bool { return true }) {
+		if problem, broken := store.containerProblem(asset.ContainerID); broken {
+			issue := IntegrityIssue{ID: asset.ID, Problem: problem, ReferencedID: asset.ContainerID}
+			report.Assets = append(report.Assets, issue)
+		}
+
+		if asset.VideoID == 0 {
+			continue
+		}
+
+		video, ok := store.videos[asset.VideoID]
+		issue := IntegrityIssue{ID: asset.ID, ReferencedID: asset.VideoID}
+
+		switch {
+		case !ok:
+			issue.Problem = VideoMissing
+		case video.DeletedAt.Valid:
+			issue.Problem = VideoDeleted
+		case video.ContainerID != asset.ContainerID:
+			issue.Problem = VideoContainerMismatch
+		default:
+			continue
+		}
+
+		report.Assets = append(report.Assets, issue)
+	}
+
+	for _, video := range store.findVideos(func(Video) bool { return true }) {
+		if problem, broken := store.containerProblem(video.ContainerID); broken {
+			issue := IntegrityIssue{ID: video.ID, Problem: problem, ReferencedID: video.ContainerID}
+			report.Videos = append(report.Videos, issue)
+		}
+	}
+
+	report.Assets = sortIssues(report.Assets)
+	report.Videos = sortIssues(report.Videos)
+
+	return report, nil
+}
+
+/* ***************************************************** Audit ****************************************************** */
+
+// GetAuditEvents get a page of the audit events in memory matching filter.
+func (store *memoryStore) GetAuditEvents(
+	ctx context.Context,
+	filter AuditFilter,
+	page Page,
+) (Connection[AuditEvent], error) {
+	store.logger.Debug("Getting audit events", zap.Any("filter", filter))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	events := make([]AuditEvent, 0, 16)
+
+	for _, event := range store.auditEvents {
+		if (filter.Actor == "" || event.Actor == filter.Actor) &&
+			(filter.EntityType == "" || event.EntityType == filter.EntityType) &&
+			(filter.EntityID == 0 || event.EntityID == filter.EntityID) &&
+			withinRange(&event.OccurredAt, filter.Since, nil) {
+			events = append(events, event)
+		}
+	}
+
+	return paginateSlice(events, auditEventKeyset, page, Order{})
+}
+
+/* ***************************************************** Import ***************************************************** */
+
+// ImportCatalog import catalog into memory.
+func (store *memoryStore) ImportCatalog(
+	ctx context.Context,
+	catalog *Catalog,
+	options ImportOptions,
+) (CatalogResults, error) {
+	store.logger.Debug(
+		"Importing catalog",
+		zap.Int("containers", len(catalog.Containers)),
+		zap.Int("videos", len(catalog.Videos)),
+		zap.Int("assets", len(catalog.Assets)),
+		zap.Bool("continueOnError", options.ContinueOnError),
+		zap.Bool("dryRun", options.DryRun),
+	)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	saved := store.checkpoint()
+	defer store.release()
+	results := importCatalog(catalog, store.atomically, catalogOps{
+		createAsset:     func(asset *Asset) error { return store.createAsset(ctx, asset) },
+		createContainer: func(container *Container) error { return store.createContainer(ctx, container) },
+		createVideo:     func(video *Video) error { return store.createVideo(ctx, video) },
+		find:            store.findExternalID,
+		updateAsset:     func(asset *Asset) error { return store.updateAsset(ctx, asset) },
+		updateContainer: func(container *Container) error { return store.updateContainer(ctx, container) },
+		updateVideo:     func(video *Video) error { return store.updateVideo(ctx, video) },
+	})
+
+	if options.DryRun || (!options.ContinueOnError && results.failed()) {
+		store.restore(saved)
+	}
+
+	return results, nil
+}
+
+/* ***************************************************** Trash ****************************************************** */
+
+// Purge permanently delete the assets, containers, and videos deleted before deletedBefore from memory.
+func (store *memoryStore) Purge(ctx context.Context, deletedBefore time.Time) (PurgeResult, error) {
+	store.logger.Debug("Purging", zap.Time("deletedBefore", deletedBefore))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var result PurgeResult
+
+	for _, assetID := range sortedIDs(store.assets) {
+		asset := store.assets[assetID]
+		if !asset.DeletedAt.Valid || !asset.DeletedAt.Time.Before(deletedBefore) {
+			continue
+		}
+
+		if err := store.record(ctx, AuditPurge, asset, nil); err != nil {
+			return result, err
+		}
+
+		delete(store.assets, assetID)
+		result.Assets++
+	}
+
+	referencedVideos := make(map[uint]bool)
+	referencedContainers := make(map[uint]bool)
+
+	for _, asset := range store.assets {
+		referencedVideos[asset.VideoID] = true
+		referencedContainers[asset.ContainerID] = true
+	}
+
+	for _, videoID := range sortedIDs(store.videos) {
+		video := store.videos[videoID]
+		if !video.DeletedAt.Valid || !video.DeletedAt.Time.Before(deletedBefore) || referencedVideos[videoID] {
+			referencedContainers[video.ContainerID] = true
+
+			continue
+		}
+
+		if err := store.record(ctx, AuditPurge, video, nil); err != nil {
+			return result, err
+		}
+
+		delete(store.videos, videoID)
+		delete(store.revisions, videoID)
+		result.Videos++
+	}
+
+	for _, containerID := range sortedIDs(store.containers) {
+		container := store.containers[containerID]
+		purgeable := container.DeletedAt.Valid && container.DeletedAt.Time.Before(deletedBefore)
+		if !purgeable || referencedContainers[containerID] {
+			continue
+		}
+
+		if err := store.record(ctx, AuditPurge, container, nil); err != nil {
+			return result, err
+		}
+
+		delete(store.containers, containerID)
+		result.Containers++
+	}
+
+	return result, nil
+}
+
+/* ***************************************************** Expiry ***************************************************** */
+
+// ExpireVideo apply action to the expired video matching videoID and mark it processed as of now.
+func (store *memoryStore) ExpireVideo(ctx context.Context, videoID uint, action ExpiryAction, now time.Time) error {
+	store.logger.Debug("Expiring video", zap.Uint("videoID", videoID), zap.String("action", string(action)))
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	video, ok := store.videos[videoID]
+	if !ok || video.DeletedAt.Valid || video.ExpiryProcessedAt != nil {
+		return ErrNotFound
+	}
+
+	video.ExpiryProcessedAt = &now
+
+	switch action {
+	case ExpiryArchive:
+		video.ArchivedAt = &now
+	case ExpiryDelete:
+		video.DeletedAt = deletedAt(now)
+	}
+
+	return store.put(ctx, AuditExpire, &video)
+}
+
+// GetExpiredVideos get up to limit videos that expired as of now and have not been processed.
+func (store *memoryStore) GetExpiredVideos(ctx context.Context, now time.Time, limit int) ([]Video, error) {
+	store.logger.Debug("Getting expired videos", zap.Time("now", now), zap.Int("limit", limit))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	videos := store.findVideos(func(video Video) bool { return video.Expired(now) && video.ExpiryProcessedAt == nil })
+	if len(videos) > limit {
+		videos = videos[:limit]
+	}
+
+	return videos, nil
+}
+
+// TryLock take a process-local lock; a memory store is never shared between processes.
+func (store *memoryStore) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	return store.locks.tryLock(key)
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// atomically run write, rolling the store back to its state before if it fails.
+func (store *memoryStore) atomically(write func() error) error {
+	saved := store.checkpoint()
+	defer store.release()
+
+	err := write()
+	if err != nil {
+		store.restore(saved)
+	}
+
+	return err
+}
+
+// checkAssetOwners check the container of asset like checkContainer, then its video like checkAssetVideo.
+func (store *memoryStore) checkAssetOwners(asset Asset) error {
+	if err := store.checkContainer(asset.ContainerID); err != nil {
+		return err
+	}
+
+	return store.checkAssetVideo(asset)
+}
+
+// checkAssetVideo ErrMissingVideo or ErrVideoContainerMismatch unless asset's video, if any, is live and matches.
+func (store *memoryStore) checkAssetVideo(asset Asset) error {
+	if asset.VideoID == 0 {
+		return nil
+	}
+
+	video, ok := store.videos[asset.VideoID]
+
+	switch {
+	case !ok || video.DeletedAt.Valid:
+		return ErrMissingVideo
+	case video.ContainerID != asset.ContainerID:
+		return ErrVideoContainerMismatch
+	default:
+		return nil
+	}
+}
+
+// checkContainer ErrMissingContainer or ErrDeletedOwner unless the container matching containerID is live.
+func (store *memoryStore) checkContainer(containerID uint) error {
+	container, ok := store.containers[containerID]
+
+	switch {
+	case !ok:
+		return ErrMissingContainer
+	case container.DeletedAt.Valid:
+		return ErrDeletedOwner
+	default:
+		return nil
+	}
+}
+
+// checkpoint mark the state of the store, which restore rolls back to until release.
+func (store *memoryStore) checkpoint() memoryCheckpoint {
+	store.checkpoints++
+
+	return memoryCheckpoint{auditEvents: store.auditEvents, undo: len(store.undoLog)}
+}
+
+// containerProblem what is wrong with a reference to the container matching containerID, if anything.
+func (store *memoryStore) containerProblem(containerID uint) (IntegrityProblem, bool) {
+	container, ok := store.containers[containerID]
+
+	switch {
+	case !ok:
+		return ContainerMissing, true
+	case container.DeletedAt.Valid:
+		return ContainerDeleted, true
+	default:
+		return "", false
+	}
+}
+
+// containsFold whether text contains substring, ignoring case.
+func containsFold(text string, substring string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(substring))
+}
+
+// createAsset create asset, checking its video and external ID like CreateAsset.
+func (store *memoryStore) createAsset(ctx context.Context, asset *Asset) error {
+	err := errors.Join(
+		store.checkAssetOwners(*asset),
+		checkExternalID(store.findExternalID, "assets", asset.ExternalID),
+	)
+	if err != nil {
+		return err
+	}
+
+	asset.Model = store.newModel("assets")
+
+	return store.put(ctx, AuditCreate, asset)
+}
+
+// createContainer create container, without its assets and videos, checking its external ID like CreateContainer.
+func (store *memoryStore) createContainer(ctx context.Context, container *Container) error {
+	if err := checkExternalID(store.findExternalID, "containers", container.ExternalID); err != nil {
+		return err
+	}
+
+	container.Model = store.newModel("containers")
+
+	stored := Container{
+		Model:       container.Model,
+		Description: container.Description,
+		ExternalID:  container.ExternalID,
+		Name:        container.Name,
+	}
+	err := store.put(ctx, AuditCreate, &stored)
+	container.Version = stored.Version
+
+	return err
+}
+
+// createVideo create video, without its assets, checking its external ID like CreateVideo.
+func (store *memoryStore) createVideo(ctx context.Context, video *Video) error {
+	err := errors.Join(
+		store.checkContainer(video.ContainerID),
+		checkExternalID(store.findExternalID, "videos", video.ExternalID),
+	)
+	if err != nil {
+		return err
+	}
+
+	video.Model = store.newModel("videos")
+	stored := *video
+	stored.Assets = nil
+	err = store.put(ctx, AuditCreate, &stored)
+	video.Version = stored.Version
+
+	return err
+}
+
+// deletedAt soft-delete marker for time t.
+func deletedAt(t time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: t, Valid: true}
+}
+
+// findAssets live assets matching predicate, ordered by ID.
+func (store *memoryStore) findAssets(predicate func(Asset) bool) []Asset {
+	assets := make([]Asset, 0, 16)
+
+	for _, asset := range store.assets {
+		if !asset.DeletedAt.Valid && predicate(asset) {
+			assets = append(assets, asset)
+		}
+	}
+
+	sort.Slice(assets, func(i, j int) bool { return assets[i].ID < assets[j].ID })
+
+	return assets
+}
+
+// findExternalID find func of catalogOps, looking records up in memory.
+func (store *memoryStore) findExternalID(table string, externalID string) (externalRecord, error) {
+	matches := func(id *string) bool { return id != nil && *id == externalID }
+
+	switch table {
+	case "assets":
+		for _, asset := range store.assets {
+			if matches(asset.ExternalID) {
+				return externalRecord{DeletedAt: asset.DeletedAt, ID: asset.ID, Version: asset.Version}, nil
+			}
+		}
+	case "containers":
+		for _, container := range store.containers {
+			if matches(container.ExternalID) {
+				return externalRecord{DeletedAt: container.DeletedAt, ID: container.ID, Version: container.Version}, nil
+			}
+		}
+	case "videos":
+		for _, video := range store.videos {
+			if matches(video.ExternalID) {
+				return externalRecord{DeletedAt: video.DeletedAt, ID: video.ID, Version: video.Version}, nil
+			}
+		}
+	}
+
+	return externalRecord{}, ErrNotFound
+}
+
+// findRevision revision number revision of the video matching videoID.
+func (store *memoryStore) findRevision(videoID uint, revision uint) (VideoRevision, error) {
+	revisions := store.revisions[videoID]
+	if revision == 0 || revision > uint(len(revisions)) {
+		return VideoRevision{}, ErrNotFound
+	}
+
+	return revisions[revision-1], nil
+}
+
+// findVideos live videos matching predicate, ordered by ID.
+func (store *memoryStore) findVideos(predicate func(Video) bool) []Video {
+	videos := make([]Video, 0, 16)
+
+	for _, video := range store.videos {
+		if !video.DeletedAt.Valid && predicate(video) {
+			videos = append(videos, video)
+		}
+	}
+
+	sort.Slice(videos, func(i, j int) bool { return videos[i].ID < videos[j].ID })
+
+	return videos
+}
+
+// matchesAssetFilter whether asset satisfies filter, other than IncludeExpired.
+func matchesAssetFilter(asset Asset, filter AssetFilter) bool {
+	return (filter.AssetType == "" || asset.AssetType == filter.AssetType) &&
+		containsFold(asset.Name, filter.Name) &&
+		withinRange(&asset.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) &&
+		withinRange(&asset.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore)
+}
+
+// matchesVideoFilter whether video satisfies filter as of now.
+func matchesVideoFilter(video Video, filter VideoFilter, now time.Time) bool {
+	if !filter.IncludeExpired && video.Expired(now) {
+		return false
+	}
+
+	return (filter.VideoType == "" || video.VideoType == filter.VideoType) &&
+		containsFold(video.Title, filter.Title) &&
+		withinRange(&video.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) &&
+		withinRange(video.ExpirationDate, filter.ExpiresAfter, filter.ExpiresBefore) &&
+		withinRange(&video.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore)
+}
+
+// moveAssets move every asset of the video matching videoID, deleted or not, to the container matching containerID.
+func (store *memoryStore) moveAssets(ctx context.Context, videoID uint, containerID uint) error {
+	for _, assetID := range sortedIDs(store.assets) {
+		asset := store.assets[assetID]
+		if asset.VideoID != videoID || asset.ContainerID == containerID {
+			continue
+		}
+
+		asset.ContainerID = containerID
+
+		if err := store.put(ctx, AuditUpdate, &asset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newModel allocate the next ID for table.
+func (store *memoryStore) newModel(table string) gorm.Model {
+	now := time.Now()
+	remember(store, store.nextID, table)
+	store.nextID[table]++
+
+	return gorm.Model{ID: store.nextID[table], CreatedAt: now, UpdatedAt: now}
+}
+
+// put store record, an *Asset, *Container, or *Video, bumping its version and recording the change.
+func (store *memoryStore) put(ctx context.Context, operation AuditOperation, record interface{}) error {
+	var before, after interface{}
+
+	switch record := record.(type) {
+	case *Asset:
+		existing, ok := store.assets[record.ID]
+		if ok {
+			before = existing
+		}
+
+		record.Version = existing.Version + 1
+		remember(store, store.assets, record.ID)
+		store.assets[record.ID], after = *record, *record
+	case *Container:
+		existing, ok := store.containers[record.ID]
+		if ok {
+			before = existing
+		}
+
+		record.Version = existing.Version + 1
+		remember(store, store.containers, record.ID)
+		store.containers[record.ID], after = *record, *record
+	case *Video:
+		existing, ok := store.videos[record.ID]
+		if ok {
+			before = existing
+		}
+
+		record.Version = existing.Version + 1
+		remember(store, store.videos, record.ID)
+		store.videos[record.ID], after = *record, *record
+	}
+
+	return store.record(ctx, operation, before, after)
+}
+
+// record append an audit event of operation, and a revision for videos, unless the change left the record as it was.
+func (store *memoryStore) record(
+	ctx context.Context,
+	operation AuditOperation,
+	before interface{},
+	after interface{},
+) error {
+	event, changed, err := newAuditEvent(ctx, operation, before, after)
+	if err != nil || !changed {
+		return err
+	}
+
+	remember(store, store.nextID, "audit_events")
+	store.nextID["audit_events"]++
+	event.ID = store.nextID["audit_events"]
+	store.auditEvents = append(store.auditEvents, event)
+
+	if video, ok := after.(Video); ok {
+		revision := newVideoRevision(event, video)
+		remember(store, store.nextID, "video_revisions")
+		remember(store, store.revisions, video.ID)
+		store.nextID["video_revisions"]++
+		revision.ID = store.nextID["video_revisions"]
+		revision.Revision = uint(len(store.revisions[video.ID]) + 1)
+		store.revisions[video.ID] = append(store.revisions[video.ID], revision)
+	}
+
+	return nil
+}
+
+// release end the checkpoint taken last, dropping the undo log once none is left.
+func (store *memoryStore) release() {
+	store.checkpoints--
+	if store.checkpoints == 0 {
+		store.undoLog = nil
+	}
+}
+
+// remember log how to put back the entry of key in entries, or its absence. Call it before each write to the maps.
+func remember[K comparable, V any](store *memoryStore, entries map[K]V, key K) {
+	if store.checkpoints == 0 {
+		return
+	}
+
+	prior, existed := entries[key]
+	store.undoLog = append(store.undoLog, func() {
+		if existed {
+			entries[key] = prior
+		} else {
+			delete(entries, key)
+		}
+	})
+}
+
+// requireLiveOwners ErrDeletedOwner unless the container matching containerID, and its video videoID if any, are live.
+func (store *memoryStore) requireLiveOwners(containerID uint, videoID uint) error {
+	container, containerFound := store.containers[containerID]
+	video, videoFound := store.videos[videoID]
+
+	if !containerFound || container.DeletedAt.Valid || (videoID != 0 && (!videoFound || video.DeletedAt.Valid)) {
+		return ErrDeletedOwner
+	}
+
+	return nil
+}
+
+// restore roll the store back to its state at saved, undoing the writes since in reverse order.
+func (store *memoryStore) restore(saved memoryCheckpoint) {
+	for i := len(store.undoLog) - 1; i >= saved.undo; i-- {
+		store.undoLog[i]()
+	}
+
+	store.auditEvents = saved.auditEvents
+	store.undoLog = store.undoLog[:saved.undo]
+}
+
+// restoreContents restore the videos and assets deleted along with the deleted container, as of now.
+func (store *memoryStore) restoreContents(ctx context.Context, container Container, now time.Time) error {
+	deletedWith := func(containerID uint, deletedAt gorm.DeletedAt) bool {
+		return containerID == container.ID && deletedAt.Valid && deletedAt.Time.Equal(container.DeletedAt.Time)
+	}
+
+	for _, videoID := range sortedIDs(store.videos) {
+		video := store.videos[videoID]
+		if !deletedWith(video.ContainerID, video.DeletedAt) {
+			continue
+		}
+
+		video.DeletedAt = gorm.DeletedAt{}
+		video.UpdatedAt = now
+
+		if err := store.put(ctx, AuditRestore, &video); err != nil {
+			return err
+		}
+	}
+
+	for _, assetID := range sortedIDs(store.assets) {
+		asset := store.assets[assetID]
+		if !deletedWith(asset.ContainerID, asset.DeletedAt) || store.checkAssetVideo(asset) != nil {
+			continue
+		}
+
+		asset.DeletedAt = gorm.DeletedAt{}
+		asset.UpdatedAt = now
+
+		if err := store.put(ctx, AuditRestore, &asset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedIDs keys of rows, in order.
+func sortedIDs[T any](rows map[uint]T) []uint {
+	ids := make([]uint, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// updateAsset update asset, checking its video like CreateAsset.
+func (store *memoryStore) updateAsset(ctx context.Context, asset *Asset) error {
+	existing, ok := store.assets[asset.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	if existing.Version != asset.Version {
+		return ErrVersionConflict
+	}
+
+	if err := store.checkAssetOwners(*asset); err != nil {
+		return err
+	}
+
+	asset.Model = gorm.Model{ID: existing.ID, CreatedAt: existing.CreatedAt, UpdatedAt: time.Now()}
+	asset.ExternalID = existing.ExternalID
+
+	return store.put(ctx, AuditUpdate, asset)
+}
+
+// updateContainer update the name and description of container, then reload it.
+func (store *memoryStore) updateContainer(ctx context.Context, container *Container) error {
+	existing, ok := store.containers[container.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	if existing.Version != container.Version {
+		return ErrVersionConflict
+	}
+
+	existing.Description = container.Description
+	existing.Name = container.Name
+	existing.UpdatedAt = time.Now()
+
+	if err := store.put(ctx, AuditUpdate, &existing); err != nil {
+		return err
+	}
+
+	*container = existing
+
+	return nil
+}
+
+// updateVideo update video, moving its assets along.
+func (store *memoryStore) updateVideo(ctx context.Context, video *Video) error {
+	existing, ok := store.videos[video.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	if existing.Version != video.Version {
+		return ErrVersionConflict
+	}
+
+	video.Model = gorm.Model{ID: existing.ID, CreatedAt: existing.CreatedAt, UpdatedAt: time.Now()}
+	video.ArchivedAt = existing.ArchivedAt
+	video.ExpiryProcessedAt = existing.ExpiryProcessedAt
+	video.ExternalID = existing.ExternalID
+	stored := *video
+	stored.Assets = nil
+
+	if err := store.checkContainer(video.ContainerID); err != nil {
+		return err
+	}
+
+	err := store.atomically(func() error {
+		if err := store.moveAssets(ctx, video.ID, video.ContainerID); err != nil {
+			return err
+		}
+
+		return store.put(ctx, AuditUpdate, &stored)
+	})
+	video.Version = stored.Version
+
+	return err
+}
+
+// videoExpired whether the video matching videoID exists and has expired as of now.
+func (store *memoryStore) videoExpired(videoID uint, now time.Time) bool {
+	video, ok := store.videos[videoID]
+
+	return ok && video.Expired(now)
+}
+
+// withinRange whether t is within the inclusive range from after to before, either of which may be nil.
+func withinRange(t *time.Time, after *time.Time, before *time.Time) bool {
+	if t == nil {
+		return after == nil && before == nil
+	}
+
+	return (after == nil || !t.Before(*after)) && (before == nil || !t.After(*before))
+}
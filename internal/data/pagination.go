@@ -0,0 +1,494 @@
+package data
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize number of results returned when a page sets neither First nor Last.
+	DefaultPageSize = 50
+	// MaxPageSize largest First or Last a page may request.
+	MaxPageSize = 100
+)
+
+// ErrInvalidCursor returned when a page's After or Before cursor was not issued by this package for the same order.
+var ErrInvalidCursor = apperr.New(apperr.Validation, "invalid cursor")
+
+// neverExpires sort key standing in for a missing expiration date, so that videos which never expire sort last.
+var neverExpires = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// assetKeyset how assets are paginated.
+var assetKeyset = keyset[Asset]{
+	id: func(asset Asset) uint { return asset.ID },
+	keys: map[OrderField]sortKey[Asset]{
+		OrderByCreatedAt: {
+			column: "assets.created_at",
+			time:   true,
+			value:  func(asset Asset) any { return asset.CreatedAt },
+		},
+		OrderByName: {
+			column: "LOWER(assets.name)",
+			value:  func(asset Asset) any { return strings.ToLower(asset.Name) },
+		},
+		OrderByUpdatedAt: {
+			column: "assets.updated_at",
+			time:   true,
+			value:  func(asset Asset) any { return asset.UpdatedAt },
+		},
+	},
+	table: "assets",
+}
+
+// auditEventKeyset how audit events are paginated, always in the order they were recorded.
+var auditEventKeyset = keyset[AuditEvent]{
+	id:    func(event AuditEvent) uint { return event.ID },
+	table: "audit_events",
+}
+
+// containerKeyset how containers are paginated.
+var containerKeyset = keyset[Container]{
+	id:    func(container Container) uint { return container.ID },
+	table: "containers",
+}
+
+// videoKeyset how videos are paginated.
+var videoKeyset = keyset[Video]{
+	id: func(video Video) uint { return video.ID },
+	keys: map[OrderField]sortKey[Video]{
+		OrderByCreatedAt: {
+			column: "videos.created_at",
+			time:   true,
+			value:  func(video Video) any { return video.CreatedAt },
+		},
+		OrderByExpirationDate: {
+			column: "COALESCE(videos.expiration_date, ?)",
+			time:   true,
+			value: func(video Video) any {
+				if video.ExpirationDate == nil {
+					return neverExpires
+				}
+
+				return *video.ExpirationDate
+			},
+			vars: []any{neverExpires},
+		},
+		OrderByTitle: {
+			column: "LOWER(videos.title)",
+			value:  func(video Video) any { return strings.ToLower(video.Title) },
+		},
+		OrderByUpdatedAt: {
+			column: "videos.updated_at",
+			time:   true,
+			value:  func(video Video) any { return video.UpdatedAt },
+		},
+	},
+	table: "videos",
+}
+
+// videoRevisionKeyset how video revisions are paginated, always in the order they were recorded.
+var videoRevisionKeyset = keyset[VideoRevision]{
+	id:    func(revision VideoRevision) uint { return revision.ID },
+	table: "video_revisions",
+}
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Connection one page of results, in the shape of a Relay connection.
+type Connection[T any] struct {
+	// Edges results on this page, in order.
+	Edges []Edge[T]
+	// HasNextPage whether results follow this page.
+	HasNextPage bool
+	// HasPreviousPage whether results precede this page.
+	HasPreviousPage bool
+	// TotalCount number of results across all pages.
+	TotalCount int64
+}
+
+// Edge result and the cursor identifying its position.
+type Edge[T any] struct {
+	// Cursor opaque position of Node, for use as a Page's After or Before.
+	Cursor string
+	// Node result.
+	Node T
+}
+
+// Order sort order for a list. The zero value sorts by ID, ascending.
+type Order struct {
+	// Descending sort largest first.
+	Descending bool
+	// Field sort key; results with equal keys are sorted by ID. Names and titles sort ignoring case, and videos that
+	// never expire sort after those that do.
+	Field OrderField
+}
+
+// OrderField field a list may be sorted by (CREATED_AT, EXPIRATION_DATE, ID, NAME, TITLE, or UPDATED_AT). Not every
+// list supports every field.
+type OrderField string
+
+const (
+	// OrderByCreatedAt sort by creation time.
+	OrderByCreatedAt OrderField = "CREATED_AT"
+	// OrderByExpirationDate sort videos by expiration date.
+	OrderByExpirationDate OrderField = "EXPIRATION_DATE"
+	// OrderByID sort by ID.
+	OrderByID OrderField = "ID"
+	// OrderByName sort assets by name.
+	OrderByName OrderField = "NAME"
+	// OrderByTitle sort videos by title.
+	OrderByTitle OrderField = "TITLE"
+	// OrderByUpdatedAt sort by last update time.
+	OrderByUpdatedAt OrderField = "UPDATED_AT"
+)
+
+// Page Relay-style pagination arguments. Results are paged by keyset, so cursors stay valid as rows are added or
+// removed.
+type Page struct {
+	// After only results after this cursor.
+	After *string
+	// Before only results before this cursor.
+	Before *string
+	// First return the first n results.
+	First *int
+	// Last return the last n results.
+	Last *int
+}
+
+// cursor encoded position in a result set.
+type cursor struct {
+	// Field sort key the position is in, or empty if sorted by ID.
+	Field OrderField `json:"field,omitempty"`
+	// ID primary key of the row at this position.
+	ID uint `json:"id"`
+	// Key sort key of the row at this position, or empty if sorted by ID.
+	Key string `json:"key,omitempty"`
+}
+
+// keyset how the rows of one table are paginated.
+type keyset[T any] struct {
+	// id primary key of a row.
+	id func(T) uint
+	// keys sort keys, besides ID, the rows may be ordered by.
+	keys map[OrderField]sortKey[T]
+	// table name qualifying the table's columns.
+	table string
+}
+
+// pageBounds decoded and validated Page and Order.
+type pageBounds[T any] struct {
+	after      *position
+	before     *position
+	descending bool
+	field      OrderField
+	key        *sortKey[T]
+	limit      int
+	reverse    bool
+}
+
+// position decoded position in a result set.
+type position struct {
+	id  uint
+	key any
+}
+
+// sortKey key, besides ID, that rows may be ordered by.
+type sortKey[T any] struct {
+	// column SQL expression the rows are ordered by.
+	column string
+	// time whether the key is a time.Time rather than a string.
+	time bool
+	// value key of a row.
+	value func(T) any
+	// vars bound to the placeholders in column.
+	vars []any
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// compareKeys compare two sort keys of the same kind.
+func compareKeys(a any, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
+
+// encodeCursor opaque cursor for position.
+func encodeCursor(position cursor) string {
+	raw, _ := json.Marshal(position)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// newConnection build a connection from up to bounds.limit+1 rows fetched in page order (reversed when paging
+// backwards); the extra row only signals that another page exists.
+func newConnection[T any](rows []T, set keyset[T], bounds pageBounds[T], total int64) Connection[T] {
+	hasMore := len(rows) > bounds.limit
+	if hasMore {
+		rows = rows[:bounds.limit]
+	}
+
+	if bounds.reverse {
+		slices.Reverse(rows)
+	}
+
+	connection := Connection[T]{Edges: make([]Edge[T], 0, len(rows)), TotalCount: total}
+
+	for _, row := range rows {
+		position := cursor{ID: set.id(row)}
+
+		if bounds.key != nil {
+			position.Field = bounds.field
+
+			switch key := bounds.key.value(row).(type) {
+			case string:
+				position.Key = key
+			case time.Time:
+				position.Key = key.UTC().Format(time.RFC3339Nano)
+			}
+		}
+
+		connection.Edges = append(connection.Edges, Edge[T]{Cursor: encodeCursor(position), Node: row})
+	}
+
+	if bounds.reverse {
+		connection.HasPreviousPage = hasMore
+		connection.HasNextPage = bounds.before != nil
+	} else {
+		connection.HasNextPage = hasMore
+		connection.HasPreviousPage = bounds.after != nil
+	}
+
+	return connection
+}
+
+// newPageBounds validate and decode page and order for rows paginated by set.
+func newPageBounds[T any](page Page, order Order, set keyset[T]) (pageBounds[T], error) {
+	bounds := pageBounds[T]{descending: order.Descending, limit: DefaultPageSize}
+
+	if order.Field != "" && order.Field != OrderByID {
+		key, ok := set.keys[order.Field]
+		if !ok {
+			return bounds, apperr.Errorf(apperr.Validation, "%s cannot be sorted by %s", set.table, order.Field)
+		}
+
+		bounds.field = order.Field
+		bounds.key = &key
+	}
+
+	if page.First != nil && page.Last != nil {
+		return bounds, apperr.New(apperr.Validation, "first and last must not both be set")
+	}
+
+	if page.First != nil {
+		bounds.limit = *page.First
+	}
+
+	if page.Last != nil {
+		bounds.limit = *page.Last
+		bounds.reverse = true
+	}
+
+	if bounds.limit < 0 || bounds.limit > MaxPageSize {
+		return bounds, apperr.Errorf(apperr.Validation, "page size must be between 0 and %d", MaxPageSize)
+	}
+
+	var err error
+
+	if page.After != nil {
+		if bounds.after, err = bounds.decodeCursor(*page.After); err != nil {
+			return bounds, err
+		}
+	}
+
+	if page.Before != nil {
+		if bounds.before, err = bounds.decodeCursor(*page.Before); err != nil {
+			return bounds, err
+		}
+	}
+
+	return bounds, nil
+}
+
+// paginateQuery run query against the table of set for one page, sorted by order. preload, if not nil, is applied
+// when fetching the page's rows but not when counting them.
+func paginateQuery[T any](
+	query *gorm.DB,
+	set keyset[T],
+	page Page,
+	order Order,
+	preload func(*gorm.DB) *gorm.DB,
+) (Connection[T], error) {
+	bounds, err := newPageBounds(page, order, set)
+	if err != nil {
+		return Connection[T]{}, err
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return Connection[T]{}, translateError(err)
+	}
+
+	after, before := ">", "<"
+	if bounds.descending {
+		after, before = before, after
+	}
+
+	if bounds.after != nil {
+		query = query.Where(bounds.seek(set.table, *bounds.after, after))
+	}
+
+	if bounds.before != nil {
+		query = query.Where(bounds.seek(set.table, *bounds.before, before))
+	}
+
+	direction := " ASC"
+	if bounds.descending != bounds.reverse {
+		direction = " DESC"
+	}
+
+	orderBy := clause.Expr{SQL: set.table + ".id" + direction}
+	if bounds.key != nil {
+		orderBy.SQL = bounds.key.column + direction + ", " + orderBy.SQL
+		orderBy.Vars = bounds.key.vars
+	}
+
+	if preload != nil {
+		query = query.Scopes(preload)
+	}
+
+	var rows []T
+	err = query.
+		Order(clause.OrderBy{Expression: orderBy}).
+		Limit(bounds.limit + 1).
+		Find(&rows).
+		Error
+	if err != nil {
+		return Connection[T]{}, translateError(err)
+	}
+
+	return newConnection(rows, set, bounds, total), nil
+}
+
+// paginateSlice page through rows, in any order, sorted by order.
+func paginateSlice[T any](rows []T, set keyset[T], page Page, order Order) (Connection[T], error) {
+	bounds, err := newPageBounds(page, order, set)
+	if err != nil {
+		return Connection[T]{}, err
+	}
+
+	positionOf := func(row T) position {
+		if bounds.key == nil {
+			return position{id: set.id(row)}
+		}
+
+		return position{id: set.id(row), key: bounds.key.value(row)}
+	}
+
+	selected := make([]T, 0, len(rows))
+
+	for _, row := range rows {
+		rowPosition := positionOf(row)
+
+		if (bounds.after == nil || bounds.compare(rowPosition, *bounds.after) > 0) &&
+			(bounds.before == nil || bounds.compare(rowPosition, *bounds.before) < 0) {
+			selected = append(selected, row)
+		}
+	}
+
+	slices.SortFunc(selected, func(a T, b T) int {
+		result := bounds.compare(positionOf(a), positionOf(b))
+
+		if bounds.reverse {
+			return -result
+		}
+
+		return result
+	})
+
+	if len(selected) > bounds.limit+1 {
+		selected = selected[:bounds.limit+1]
+	}
+
+	return newConnection(selected, set, bounds, int64(len(rows))), nil
+}
+
+// compare compare two positions in the bounds' sort order.
+func (bounds pageBounds[T]) compare(a position, b position) int {
+	result := 0
+
+	if bounds.key != nil {
+		result = compareKeys(a.key, b.key)
+	}
+
+	if result == 0 {
+		result = cmp.Compare(a.id, b.id)
+	}
+
+	if bounds.descending {
+		return -result
+	}
+
+	return result
+}
+
+// decodeCursor decode an opaque cursor issued by newConnection for the same sort key.
+func (bounds pageBounds[T]) decodeCursor(text string) (*position, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var decoded cursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.ID == 0 || decoded.Field != bounds.field {
+		return nil, ErrInvalidCursor
+	}
+
+	if bounds.key == nil {
+		return &position{id: decoded.ID}, nil
+	}
+
+	if !bounds.key.time {
+		return &position{id: decoded.ID, key: decoded.Key}, nil
+	}
+
+	key, err := time.Parse(time.RFC3339Nano, decoded.Key)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &position{id: decoded.ID, key: key.UTC()}, nil
+}
+
+// seek condition matching the rows on the op (< or >) side of position.
+func (bounds pageBounds[T]) seek(table string, position position, op string) clause.Expr {
+	id := clause.Expr{SQL: table + ".id " + op + " ?", Vars: []any{position.id}}
+
+	if bounds.key == nil {
+		return id
+	}
+
+	column := bounds.key.column
+	vars := slices.Concat(bounds.key.vars, []any{position.key}, bounds.key.vars, []any{position.key}, id.Vars)
+
+	return clause.Expr{
+		SQL:  "(" + column + " " + op + " ? OR (" + column + " = ? AND " + id.SQL + "))",
+		Vars: vars,
+	}
+}
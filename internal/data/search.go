@@ -0,0 +1,208 @@
+package data
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// highlightStart marks the start of a matching word in a snippet, as in Postgres' ts_headline.
+	highlightStart = "<b>"
+	// highlightStop marks the end of a matching word in a snippet, as in Postgres' ts_headline.
+	highlightStop = "</b>"
+	// snippetWords maximum number of words in a snippet.
+	snippetWords = 20
+)
+
+// headlineOptions Postgres ts_headline options producing snippets like those of highlight.
+const headlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxWords=20, MinWords=5"
+
+// snippetEscaper HTML-escape the text of snippets, so that the highlight markers are their only markup.
+var snippetEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// SearchFilter criteria for searches.
+type SearchFilter struct {
+	// ContainerID only results in this container, or results in any container if 0.
+	ContainerID uint
+	// IncludeExpired include expired videos and their assets.
+	IncludeExpired bool
+}
+
+// SearchResult video or asset matching a search. Exactly one of Asset and Video is set.
+type SearchResult struct {
+	// Asset matching asset.
+	Asset *Asset
+	// Score relevance; higher is better. Scores are only comparable within one search.
+	Score float64
+	// Snippet HTML-escaped excerpt of the matching text, with matching words between highlightStart and
+	// highlightStop.
+	Snippet string
+	// Video matching video, along with its assets.
+	Video *Video
+}
+
+// searchField text to search, and how much a match in it counts.
+type searchField struct {
+	text   string
+	weight float64
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// highlight HTML-escaped excerpt of text around the first word matching terms, with every matching word highlighted.
+func highlight(terms []string, text string) string {
+	words := strings.Fields(text)
+	first := slices.IndexFunc(words, func(word string) bool { return matchesTerms(word, terms) })
+	start := max(0, min(first-snippetWords/4, len(words)-snippetWords))
+	end := min(len(words), start+snippetWords)
+
+	excerpt := make([]string, 0, end-start)
+
+	for _, word := range words[start:end] {
+		excerpt = append(excerpt, highlightWord(terms, word))
+	}
+
+	return strings.Join(excerpt, " ")
+}
+
+// highlightWord HTML-escaped word, with the tokens of it that start with one of terms highlighted, but not the
+// punctuation or markup around them.
+func highlightWord(terms []string, word string) string {
+	var builder strings.Builder
+
+	for word != "" {
+		first, _ := utf8.DecodeRuneInString(word)
+		end := strings.IndexFunc(word, func(r rune) bool { return isTokenRune(r) != isTokenRune(first) })
+		if end < 0 {
+			end = len(word)
+		}
+
+		escaped := snippetEscaper.Replace(word[:end])
+		if isTokenRune(first) && matchesTerms(word[:end], terms) {
+			escaped = highlightStart + escaped + highlightStop
+		}
+
+		builder.WriteString(escaped)
+		word = word[end:]
+	}
+
+	return builder.String()
+}
+
+// htmlEscapeSQL SQL expression HTML-escaping the text of expression like snippetEscaper, for ts_headline to
+// highlight.
+func htmlEscapeSQL(expression string) string {
+	return "replace(replace(replace(" + expression + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
+
+// isTokenRune whether r is part of the words that tokenize splits text into.
+func isTokenRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// matchesTerms whether a word of text starts with one of terms.
+func matchesTerms(text string, terms []string) bool {
+	for _, token := range tokenize(text) {
+		for _, term := range terms {
+			if strings.HasPrefix(token, term) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rank score fields against terms, or ok is false unless every term starts a word of some field. Matches count for
+// more in short fields than in long ones.
+func rank(terms []string, fields []searchField) (score float64, ok bool) {
+	matched := make([]bool, len(terms))
+
+	for _, field := range fields {
+		tokens := tokenize(field.text)
+		hits := 0
+
+		for _, token := range tokens {
+			for i, term := range terms {
+				if strings.HasPrefix(token, term) {
+					matched[i] = true
+					hits++
+				}
+			}
+		}
+
+		if hits > 0 {
+			score += field.weight * float64(hits) / float64(len(tokens))
+		}
+	}
+
+	return score, !slices.Contains(matched, false)
+}
+
+// rankSearchResults in-process search of videos and assets for terms, returning at most limit results ranked by
+// sortSearchResults.
+func rankSearchResults(terms []string, videos []Video, assets []Asset, limit int) []SearchResult {
+	results := make([]SearchResult, 0, len(videos)+len(assets))
+
+	for _, video := range videos {
+		fields := []searchField{{text: video.Title, weight: 1}, {text: video.Description, weight: 0.4}}
+
+		if score, ok := rank(terms, fields); ok {
+			snippet := highlight(terms, video.Title+" "+video.Description)
+			results = append(results, SearchResult{Score: score, Snippet: snippet, Video: &video})
+		}
+	}
+
+	for _, asset := range assets {
+		if score, ok := rank(terms, []searchField{{text: asset.Name, weight: 1}}); ok {
+			snippet := highlight(terms, asset.Name)
+			results = append(results, SearchResult{Asset: &asset, Score: score, Snippet: snippet})
+		}
+	}
+
+	return sortSearchResults(results, limit)
+}
+
+// sortSearchResults sort results by descending score, then videos before assets, then by ID, keeping at most limit.
+func sortSearchResults(results []SearchResult, limit int) []SearchResult {
+	slices.SortFunc(results, func(a SearchResult, b SearchResult) int {
+		if result := cmp.Compare(b.Score, a.Score); result != 0 {
+			return result
+		}
+
+		if (a.Video == nil) != (b.Video == nil) {
+			if a.Video != nil {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Compare(a.id(), b.id())
+	})
+
+	return results[:min(limit, len(results))]
+}
+
+// tokenize lower-case words of text.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isTokenRune(r) })
+}
+
+// id ID of the result's video or asset.
+func (result SearchResult) id() uint {
+	if result.Video != nil {
+		return result.Video.ID
+	}
+
+	return result.Asset.ID
+}
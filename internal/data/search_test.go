@@ -0,0 +1,39 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []string
+		text  string
+		want  string
+	}{
+		{name: "prefix match", terms: []string{"rock"}, text: "Rocket launch", want: "<b>Rocket</b> launch"},
+		{name: "no match", terms: []string{"moon"}, text: "Rocket launch", want: "Rocket launch"},
+		{
+			name:  "markup escaped",
+			terms: []string{"rocket"},
+			text:  "<script>alert(1)</script> rocket & co",
+			want:  "&lt;script&gt;alert(1)&lt;/script&gt; <b>rocket</b> &amp; co",
+		},
+		{
+			name:  "escaped match",
+			terms: []string{"rocket"},
+			text:  "<i>rocket</i>",
+			want:  "&lt;i&gt;<b>rocket</b>&lt;/i&gt;",
+		},
+		{name: "punctuation", terms: []string{"launch"}, text: "Rocket (launch)!", want: "Rocket (<b>launch</b>)!"},
+		{name: "unicode", terms: []string{"émission"}, text: "Une Émission", want: "Une <b>Émission</b>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := highlight(test.terms, test.text); got != test.want {
+				t.Errorf("highlight() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
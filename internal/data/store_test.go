@@ -0,0 +1,480 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testURL URL of the test records.
+const testURL = "https://example.com/video"
+
+// testExpiration expiration date of the video of newTestStore.
+var testExpiration = time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+/* ****************************************************************************************************************** *
+ *                                                       Tests                                                        *
+ * ****************************************************************************************************************** */
+
+func TestCreateAsset(t *testing.T) {
+	tests := []struct {
+		name        string
+		containerID uint
+		videoID     uint
+		wantErr     error
+	}{
+		{name: "without video", containerID: 1},
+		{name: "with video in its container", containerID: 1, videoID: 1},
+		{name: "with video in another container", containerID: 2, videoID: 1, wantErr: ErrVideoContainerMismatch},
+		{name: "with missing video", containerID: 1, videoID: 9, wantErr: ErrMissingVideo},
+		{name: "in deleted container", containerID: 3, wantErr: ErrDeletedOwner},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			asset := Asset{
+				AssetType:   Image,
+				ContainerID: test.containerID,
+				Name:        "poster",
+				URL:         testURL,
+				VideoID:     test.videoID,
+			}
+
+			err := newTestStore(t).CreateAsset(context.Background(), &asset)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("CreateAsset() error = %v, want %v", err, test.wantErr)
+			}
+
+			if err == nil && (asset.ID == 0 || asset.Version != 1) {
+				t.Errorf("CreateAsset() ID = %d, version = %d, want an ID and version 1", asset.ID, asset.Version)
+			}
+		})
+	}
+}
+
+func TestCreateVideos(t *testing.T) {
+	tests := []struct {
+		name            string
+		containerIDs    []uint
+		continueOnError bool
+		wantCreated     int64
+		wantErrs        []bool
+	}{
+		{name: "all valid", containerIDs: []uint{1, 1}, wantCreated: 2, wantErrs: []bool{false, false}},
+		{name: "rolled back", containerIDs: []uint{1, 3, 1}, wantErrs: []bool{false, true, false}},
+		{
+			name:            "continue on error",
+			containerIDs:    []uint{1, 3, 1},
+			continueOnError: true,
+			wantCreated:     2,
+			wantErrs:        []bool{false, true, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			videos := make([]Video, len(test.containerIDs))
+			for i, containerID := range test.containerIDs {
+				videos[i] = newTestVideo(containerID, "batch")
+			}
+
+			errs, err := store.CreateVideos(ctx, videos, test.continueOnError)
+			if err != nil {
+				t.Fatalf("CreateVideos() error = %v", err)
+			}
+
+			for i, wantErr := range test.wantErrs {
+				if (errs[i] != nil) != wantErr {
+					t.Errorf("CreateVideos() error %d = %v, want error: %t", i, errs[i], wantErr)
+				}
+			}
+
+			page, err := store.GetVideosByContainer(ctx, 1, VideoFilter{}, Order{}, Page{})
+			if err != nil {
+				t.Fatalf("GetVideosByContainer() error = %v", err)
+			}
+
+			if created := page.TotalCount - 1; created != test.wantCreated {
+				t.Errorf("CreateVideos() created %d videos, want %d", created, test.wantCreated)
+			}
+		})
+	}
+}
+
+func TestDeleteContainer(t *testing.T) {
+	tests := []struct {
+		name       string
+		onContents DeletePolicy
+		wantErr    error
+		wantVideos int
+	}{
+		{name: "cascade", onContents: Cascade},
+		{name: "restrict", onContents: Restrict, wantErr: ErrContainerNotEmpty, wantVideos: 1},
+		{name: "detach", onContents: Detach, wantErr: ErrUnsupportedPolicy, wantVideos: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			err := store.DeleteContainer(ctx, 1, test.onContents)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("DeleteContainer() error = %v, want %v", err, test.wantErr)
+			}
+
+			videos, err := store.GetVideosByIDs(ctx, []uint{1})
+			if err != nil {
+				t.Fatalf("GetVideosByIDs() error = %v", err)
+			}
+
+			if len(videos) != test.wantVideos {
+				t.Errorf("DeleteContainer() left %d videos, want %d", len(videos), test.wantVideos)
+			}
+		})
+	}
+}
+
+func TestDeleteVideo(t *testing.T) {
+	tests := []struct {
+		name        string
+		onAssets    DeletePolicy
+		wantErr     error
+		wantAssets  int
+		wantVideoID uint
+	}{
+		{name: "cascade", onAssets: Cascade},
+		{name: "detach", onAssets: Detach, wantAssets: 1},
+		{name: "restrict", onAssets: Restrict, wantErr: ErrVideoHasAssets, wantAssets: 1, wantVideoID: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			asset := Asset{AssetType: Image, ContainerID: 1, Name: "poster", URL: testURL, VideoID: 1}
+			if err := store.CreateAsset(ctx, &asset); err != nil {
+				t.Fatalf("CreateAsset() error = %v", err)
+			}
+
+			err := store.DeleteVideo(ctx, 1, test.onAssets)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("DeleteVideo() error = %v, want %v", err, test.wantErr)
+			}
+
+			assets, err := store.GetAssetsByIDs(ctx, []uint{asset.ID})
+			if err != nil {
+				t.Fatalf("GetAssetsByIDs() error = %v", err)
+			}
+
+			if len(assets) != test.wantAssets {
+				t.Fatalf("DeleteVideo() left %d assets, want %d", len(assets), test.wantAssets)
+			}
+
+			if videoID := assets[asset.ID].VideoID; test.wantAssets > 0 && videoID != test.wantVideoID {
+				t.Errorf("DeleteVideo() left the asset in video %d, want %d", videoID, test.wantVideoID)
+			}
+		})
+	}
+}
+
+func TestGetAuditEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     AuditFilter
+		wantEvents []AuditOperation
+	}{
+		{
+			name:       "record",
+			filter:     AuditFilter{EntityID: 3, EntityType: "Container"},
+			wantEvents: []AuditOperation{AuditCreate, AuditDelete},
+		},
+		{name: "type", filter: AuditFilter{EntityType: "Video"}, wantEvents: []AuditOperation{AuditCreate}},
+		{name: "actor", filter: AuditFilter{Actor: "editor"}, wantEvents: []AuditOperation{AuditUpdate}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			container := Container{Model: gorm.Model{ID: 2}, Name: "renamed", Version: 1}
+			if err := store.UpdateContainer(WithActor(ctx, "editor"), &container); err != nil {
+				t.Fatalf("UpdateContainer() error = %v", err)
+			}
+
+			events, err := store.GetAuditEvents(ctx, test.filter, Page{})
+			if err != nil {
+				t.Fatalf("GetAuditEvents() error = %v", err)
+			}
+
+			var operations []AuditOperation
+			for _, edge := range events.Edges {
+				operations = append(operations, edge.Node.Operation)
+			}
+
+			if !slices.Equal(operations, test.wantEvents) {
+				t.Errorf("GetAuditEvents() operations = %v, want %v", operations, test.wantEvents)
+			}
+		})
+	}
+}
+
+func TestPatchVideo(t *testing.T) {
+	later := testExpiration.AddDate(1, 0, 0)
+	deletedID := uint(3)
+	title := "patched"
+
+	tests := []struct {
+		name           string
+		videoID        uint
+		patch          VideoPatch
+		wantErr        error
+		wantExpiration *time.Time
+	}{
+		{
+			name:           "set expiration date",
+			videoID:        1,
+			patch:          VideoPatch{ExpirationDate: &later, Version: 1},
+			wantExpiration: &later,
+		},
+		{name: "clear expiration date", videoID: 1, patch: VideoPatch{ClearExpirationDate: true, Version: 1}},
+		{
+			name:           "keep expiration date",
+			videoID:        1,
+			patch:          VideoPatch{Title: &title, Version: 1},
+			wantExpiration: &testExpiration,
+		},
+		{name: "other version", videoID: 1, patch: VideoPatch{Title: &title, Version: 2}, wantErr: ErrVersionConflict},
+		{name: "missing video", videoID: 9, patch: VideoPatch{Title: &title, Version: 1}, wantErr: ErrNotFound},
+		{
+			name:    "into deleted container",
+			videoID: 1,
+			patch:   VideoPatch{ContainerID: &deletedID, Version: 1},
+			wantErr: ErrDeletedOwner,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := newTestStore(t).PatchVideo(context.Background(), test.videoID, test.patch)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("PatchVideo() error = %v, want %v", err, test.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !sameTime(patched.ExpirationDate, test.wantExpiration) {
+				t.Errorf("PatchVideo() expiration date = %v, want %v", patched.ExpirationDate, test.wantExpiration)
+			}
+
+			if patched.Version != 2 {
+				t.Errorf("PatchVideo() version = %d, want 2", patched.Version)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name           string
+		deletedBefore  time.Duration
+		wantContainers int64
+	}{
+		{name: "deleted before", deletedBefore: time.Hour, wantContainers: 1},
+		{name: "deleted after", deletedBefore: -time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := newTestStore(t).Purge(context.Background(), time.Now().Add(test.deletedBefore))
+			if err != nil {
+				t.Fatalf("Purge() error = %v", err)
+			}
+
+			if result.Containers != test.wantContainers {
+				t.Errorf("Purge() purged %d containers, want %d", result.Containers, test.wantContainers)
+			}
+		})
+	}
+}
+
+func TestRestoreContainer(t *testing.T) {
+	tests := []struct {
+		name         string
+		containerID  uint
+		withContents bool
+		wantErr      error
+		wantVideos   int
+	}{
+		{name: "without contents", containerID: 1},
+		{name: "with contents", containerID: 1, withContents: true, wantVideos: 1},
+		{name: "live container", containerID: 2, wantErr: ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			if err := store.DeleteContainer(ctx, 1, Cascade); err != nil {
+				t.Fatalf("DeleteContainer() error = %v", err)
+			}
+
+			_, err := store.RestoreContainer(ctx, test.containerID, test.withContents)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("RestoreContainer() error = %v, want %v", err, test.wantErr)
+			}
+
+			videos, err := store.GetVideosByIDs(ctx, []uint{1})
+			if err != nil {
+				t.Fatalf("GetVideosByIDs() error = %v", err)
+			}
+
+			if len(videos) != test.wantVideos {
+				t.Errorf("RestoreContainer() restored %d videos, want %d", len(videos), test.wantVideos)
+			}
+		})
+	}
+}
+
+func TestRestoreVideo(t *testing.T) {
+	tests := []struct {
+		name            string
+		deleteContainer bool
+		videoID         uint
+		wantErr         error
+	}{
+		{name: "deleted video", videoID: 1},
+		{name: "in deleted container", deleteContainer: true, videoID: 1, wantErr: ErrDeletedOwner},
+		{name: "live video", videoID: 2, wantErr: ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			video := newTestVideo(1, "live")
+			if err := store.CreateVideo(ctx, &video); err != nil {
+				t.Fatalf("CreateVideo() error = %v", err)
+			}
+
+			if err := store.DeleteVideo(ctx, 1, Cascade); err != nil {
+				t.Fatalf("DeleteVideo() error = %v", err)
+			}
+
+			if test.deleteContainer {
+				if err := store.DeleteContainer(ctx, 1, Cascade); err != nil {
+					t.Fatalf("DeleteContainer() error = %v", err)
+				}
+			}
+
+			if _, err := store.RestoreVideo(ctx, test.videoID, false); !errors.Is(err, test.wantErr) {
+				t.Errorf("RestoreVideo() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateVideo(t *testing.T) {
+	tests := []struct {
+		name        string
+		videoID     uint
+		containerID uint
+		version     uint
+		wantErr     error
+	}{
+		{name: "current version", videoID: 1, containerID: 1, version: 2},
+		{name: "stale version", videoID: 1, containerID: 1, version: 1, wantErr: ErrVersionConflict},
+		{name: "missing video", videoID: 9, containerID: 1, version: 2, wantErr: ErrNotFound},
+		{name: "into deleted container", videoID: 1, containerID: 3, version: 2, wantErr: ErrDeletedOwner},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			// A first change, so that the stale version is one that existed.
+			video := newTestVideo(1, "changed")
+			video.ID = 1
+			video.Version = 1
+
+			if err := store.UpdateVideo(ctx, &video); err != nil {
+				t.Fatalf("UpdateVideo() error = %v", err)
+			}
+
+			video = newTestVideo(test.containerID, "updated")
+			video.ID = test.videoID
+			video.Version = test.version
+
+			err := store.UpdateVideo(ctx, &video)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("UpdateVideo() error = %v, want %v", err, test.wantErr)
+			}
+
+			if err == nil && video.Version != test.version+1 {
+				t.Errorf("UpdateVideo() version = %d, want %d", video.Version, test.version+1)
+			}
+		})
+	}
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// newTestStore memory store holding container 1 with video 1, which expires at testExpiration, container 2, and
+// deleted container 3.
+func newTestStore(t *testing.T) Store {
+	t.Helper()
+
+	ctx := context.Background()
+	store := NewMemoryStore(zap.NewNop())
+
+	for _, name := range []string{"container", "other", "deleted"} {
+		container := Container{Name: name}
+		if err := store.CreateContainer(ctx, &container); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+
+	expiration := testExpiration
+	video := newTestVideo(1, "video")
+	video.ExpirationDate = &expiration
+
+	if err := store.CreateVideo(ctx, &video); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+
+	if err := store.DeleteContainer(ctx, 3, Cascade); err != nil {
+		t.Fatalf("DeleteContainer() error = %v", err)
+	}
+
+	return store
+}
+
+// newTestVideo unsaved video titled title in the container matching containerID.
+func newTestVideo(containerID uint, title string) Video {
+	return Video{ContainerID: containerID, PlaybackURL: testURL, Title: title, VideoType: Clip}
+}
+
+// sameTime whether a and b are both nil or the same instant.
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
@@ -0,0 +1,106 @@
+// Package dataloader batching and caching of lookups made while resolving a single request.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultWait how long a Loader waits for more keys before fetching a batch.
+const DefaultWait = 2 * time.Millisecond
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Fetch look up values by key. Keys missing from the result load as the zero value.
+type Fetch[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys loaded within a short wait of each other and looks them up with a single Fetch. Results
+// are cached for the life of the Loader, so a Loader should only serve one request.
+type Loader[K comparable, V any] struct {
+	batch   *batch[K, V]
+	cache   map[K]*batch[K, V]
+	fetch   Fetch[K, V]
+	maxSize int
+	mutex   sync.Mutex
+	wait    time.Duration
+}
+
+// batch keys fetched together, and their results once done is closed.
+type batch[K comparable, V any] struct {
+	done    chan struct{}
+	err     error
+	full    chan struct{}
+	keys    []K
+	results map[K]V
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// New create a Loader fetching batches of at most maxSize keys, or of any size if maxSize is 0, after waiting wait
+// for more keys.
+func New[K comparable, V any](fetch Fetch[K, V], wait time.Duration, maxSize int) *Loader[K, V] {
+	return &Loader[K, V]{cache: map[K]*batch[K, V]{}, fetch: fetch, maxSize: maxSize, wait: wait}
+}
+
+// Load value for key, fetched together with the keys of concurrent calls. The batch is fetched with the ctx of its
+// first call.
+func (loader *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	loader.mutex.Lock()
+
+	current, ok := loader.cache[key]
+	if !ok {
+		if loader.batch == nil {
+			loader.batch = &batch[K, V]{done: make(chan struct{}), full: make(chan struct{})}
+			go loader.dispatch(ctx, loader.batch)
+		}
+
+		current = loader.batch
+		current.keys = append(current.keys, key)
+		loader.cache[key] = current
+
+		if loader.maxSize > 0 && len(current.keys) >= loader.maxSize {
+			loader.batch = nil
+			close(current.full)
+		}
+	}
+
+	loader.mutex.Unlock()
+
+	select {
+	case <-current.done:
+		return current.results[key], current.err
+	case <-ctx.Done():
+		var zero V
+
+		return zero, ctx.Err()
+	}
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// dispatch fetch current once it is full or the wait has passed.
+func (loader *Loader[K, V]) dispatch(ctx context.Context, current *batch[K, V]) {
+	timer := time.NewTimer(loader.wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-current.full:
+	}
+
+	loader.mutex.Lock()
+	if loader.batch == current {
+		loader.batch = nil
+	}
+	loader.mutex.Unlock()
+
+	current.results, current.err = loader.fetch(ctx, current.keys)
+	close(current.done)
+}
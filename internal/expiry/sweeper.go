@@ -0,0 +1,188 @@
+// Package expiry background processing of expired videos.
+package expiry
+
+import (
+	"RocketContainer.go/internal/data"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// actor who scheduled sweeps make their changes as, in the audit log.
+const actor = "expiry-sweeper"
+
+// batchSize maximum number of expired videos processed per sweep.
+const batchSize = 500
+
+// lockKey lock electing the single replica that sweeps.
+const lockKey int64 = 7_246_014_802
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Stats outcome of a sweep.
+type Stats struct {
+	// Action applied to expired videos.
+	Action data.ExpiryAction
+	// Error why the sweep failed, if it did.
+	Error error
+	// Failed number of expired videos that could not be processed.
+	Failed int
+	// FinishedAt when the sweep finished.
+	FinishedAt time.Time
+	// Leader whether this replica held the sweeper lock; followers skip the sweep.
+	Leader bool
+	// Processed number of expired videos processed.
+	Processed int
+	// Scanned number of expired videos found.
+	Scanned int
+	// StartedAt when the sweep started.
+	StartedAt time.Time
+}
+
+// Sweeper periodically applies an ExpiryAction to videos whose expiration date has passed.
+type Sweeper struct {
+	action   data.ExpiryAction
+	interval time.Duration
+	lastRun  *Stats
+	logger   *zap.Logger
+	mutex    sync.RWMutex
+	store    data.Store
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// NewSweeper create a Sweeper applying action to expired videos in store every interval.
+func NewSweeper(store data.Store, logger *zap.Logger, action data.ExpiryAction, interval time.Duration) *Sweeper {
+	return &Sweeper{action: action, interval: interval, logger: logger, store: store}
+}
+
+// ParseAction parse an expiry action name ("archive", "delete", or "event").
+func ParseAction(name string) (data.ExpiryAction, error) {
+	switch action := data.ExpiryAction(name); action {
+	case data.ExpiryArchive, data.ExpiryDelete, data.ExpiryEvent:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown expiry action %q", name)
+	}
+}
+
+// LastRun statistics of the most recent sweep, or nil if none has finished.
+func (sweeper *Sweeper) LastRun() *Stats {
+	sweeper.mutex.RLock()
+	defer sweeper.mutex.RUnlock()
+
+	if sweeper.lastRun == nil {
+		return nil
+	}
+
+	stats := *sweeper.lastRun
+
+	return &stats
+}
+
+// Run sweep immediately and then every interval until ctx is cancelled.
+func (sweeper *Sweeper) Run(ctx context.Context) {
+	ctx = data.WithActor(ctx, actor)
+
+	sweeper.logger.Info(
+		"Starting expiry sweeper",
+		zap.String("action", string(sweeper.action)),
+		zap.Duration("interval", sweeper.interval),
+	)
+
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+
+	for {
+		sweeper.Sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			sweeper.logger.Info("Stopping expiry sweeper")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep process every video that has expired, if this replica can take the sweeper lock.
+func (sweeper *Sweeper) Sweep(ctx context.Context) Stats {
+	stats := Stats{Action: sweeper.action, StartedAt: time.Now()}
+
+	unlock, acquired, err := sweeper.store.TryLock(ctx, lockKey)
+	if err != nil {
+		stats.Error = err
+		sweeper.logger.Error("Failed to take expiry sweeper lock", zap.Error(err))
+	} else if acquired {
+		stats.Leader = true
+		sweeper.process(ctx, &stats)
+		unlock()
+	} else {
+		sweeper.logger.Debug("Another replica holds the expiry sweeper lock")
+	}
+
+	stats.FinishedAt = time.Now()
+
+	sweeper.mutex.Lock()
+	sweeper.lastRun = &stats
+	sweeper.mutex.Unlock()
+
+	return stats
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// process apply the sweeper's action to expired videos in batches, recording the outcome in stats.
+func (sweeper *Sweeper) process(ctx context.Context, stats *Stats) {
+	for ctx.Err() == nil {
+		videos, err := sweeper.store.GetExpiredVideos(ctx, stats.StartedAt, batchSize)
+		if err != nil {
+			stats.Error = err
+			sweeper.logger.Error("Failed to get expired videos", zap.Error(err))
+
+			return
+		}
+
+		stats.Scanned += len(videos)
+		processed := 0
+
+		for _, video := range videos {
+			err := sweeper.store.ExpireVideo(ctx, video.ID, sweeper.action, time.Now())
+
+			switch {
+			case errors.Is(err, data.ErrNotFound):
+				// Deleted or processed since it was scanned.
+			case err != nil:
+				stats.Failed++
+				sweeper.logger.Error("Failed to process expired video", zap.Uint("videoID", video.ID), zap.Error(err))
+			default:
+				processed++
+				sweeper.logger.Info(
+					"Video expired",
+					zap.String("event", "video.expired"),
+					zap.String("action", string(sweeper.action)),
+					zap.Uint("videoID", video.ID),
+					zap.Uint("containerID", video.ContainerID),
+					zap.Timep("expirationDate", video.ExpirationDate),
+				)
+			}
+		}
+
+		stats.Processed += processed
+
+		// A short batch is the last one; a batch with no progress would only fail the same way again.
+		if len(videos) < batchSize || processed == 0 {
+			return
+		}
+	}
+}
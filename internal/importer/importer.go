@@ -0,0 +1,221 @@
+// Package importer reading of the records of catalog import files, in JSON, NDJSON, or CSV.
+package importer
+
+import (
+	"RocketContainer.go/internal/apperr"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxLineSize longest NDJSON line read.
+const maxLineSize = 1 << 20
+
+// ErrUnknownFormat returned for import formats other than CSV, JSON, and NDJSON.
+var ErrUnknownFormat = apperr.New(apperr.Validation, "unknown import format; use csv, json, or ndjson")
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Format format of an import file.
+type Format string
+
+const (
+	// CSV comma-separated values, with a header row naming the field of each column. Empty cells are left out.
+	CSV Format = "csv"
+	// JSON an array of JSON objects.
+	JSON Format = "json"
+	// NDJSON one JSON object per line. Blank lines are skipped.
+	NDJSON Format = "ndjson"
+)
+
+// Row one record of an import file.
+type Row struct {
+	// Err why the record could not be read, or nil if it could.
+	Err error
+	// Fields fields of the record by name, as JSON values. Those read from CSV are strings.
+	Fields map[string]json.RawMessage
+	// Line line of the file that the record starts on, counting from 1.
+	Line int
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// FormatOf format of the file named name, by its extension: .csv, .json, or .ndjson or .jsonl.
+func FormatOf(name string) (Format, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return CSV, nil
+	case ".json":
+		return JSON, nil
+	case ".jsonl", ".ndjson":
+		return NDJSON, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ParseFormat format named name, ignoring case.
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(name))
+	if format != CSV && format != JSON && format != NDJSON {
+		return "", ErrUnknownFormat
+	}
+
+	return format, nil
+}
+
+// Read read the records of file, in format. Records that cannot be read are returned with their error, as long as
+// the rest of the file can still be; otherwise the file cannot be read and an error is returned.
+func Read(file io.Reader, format Format) ([]Row, error) {
+	switch format {
+	case CSV:
+		return readCSV(file)
+	case JSON:
+		return readJSON(file)
+	case NDJSON:
+		return readNDJSON(file)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// decodeObject fields of a record that should be a JSON object.
+func decodeObject(raw []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, apperr.New(apperr.Validation, "record is not a JSON object")
+	}
+
+	return fields, nil
+}
+
+// readCSV read the records of a CSV file.
+func readCSV(file io.Reader) ([]Row, error) {
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, apperr.Wrap(apperr.Validation, err, err.Error())
+	}
+
+	// Spreadsheets may save a byte order mark before the header.
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+
+	var rows []Row
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if errors.Is(err, csv.ErrFieldCount) {
+			rows = append(rows, Row{
+				Err: apperr.Errorf(
+					apperr.Validation,
+					"record has %d fields; the header has %d",
+					len(record),
+					len(header),
+				),
+				Line: line,
+			})
+
+			continue
+		} else if err != nil {
+			return nil, apperr.Wrap(apperr.Validation, err, err.Error())
+		}
+
+		fields := make(map[string]json.RawMessage, len(record))
+
+		for i, value := range record {
+			if value != "" {
+				fields[header[i]], _ = json.Marshal(value)
+			}
+		}
+
+		rows = append(rows, Row{Fields: fields, Line: line})
+	}
+}
+
+// readJSON read the records of a JSON file, which must be an array.
+func readJSON(file io.Reader) ([]Row, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, apperr.New(apperr.Validation, "file is not a JSON array")
+	}
+
+	var rows []Row
+
+	// Lines are counted from where the previous record started, so that the content is only scanned once.
+	line, counted := 1, 0
+
+	for decoder.More() {
+		// The decoder stops after the previous record, before the comma separating it from this one.
+		offset := decoder.InputOffset()
+		start := len(content) - len(bytes.TrimLeft(content[offset:], ", \t\r\n"))
+		line += bytes.Count(content[counted:start], []byte("\n"))
+		counted = start
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, apperr.Errorf(apperr.Validation, "line %d: %v", line, err)
+		}
+
+		fields, err := decodeObject(raw)
+		rows = append(rows, Row{Err: err, Fields: fields, Line: line})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, apperr.Errorf(apperr.Validation, "file is not a JSON array: %v", err)
+	}
+
+	return rows, nil
+}
+
+// readNDJSON read the records of an NDJSON file.
+func readNDJSON(file io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+
+	var rows []Row
+
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		fields, err := decodeObject(text)
+		rows = append(rows, Row{Err: err, Fields: fields, Line: line})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, apperr.Wrap(apperr.Validation, err, "file cannot be read as NDJSON: "+err.Error())
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// legacyDateLayouts layouts accepted for expiration dates stored before they were typed, most specific first.
+var legacyDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+func init() {
+	hooks[2] = parseExpirationDates
+}
+
+// parseExpirationDates copy videos.expiration_date_text into the typed expiration_date column, reporting rows that
+// fail to parse in expiration_date_parse_failures, then drop the text column.
+func parseExpirationDates(tx *gorm.DB, logger *zap.Logger) error {
+	var rows []struct {
+		ExpirationDateText *string
+		ID                 uint
+	}
+
+	if err := tx.Table("videos").Select("id", "expiration_date_text").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	failed := 0
+	now := time.Now().UTC()
+
+	for _, row := range rows {
+		if row.ExpirationDateText == nil {
+			continue
+		}
+
+		expirationDate, ok := parseLegacyDate(*row.ExpirationDateText)
+
+		if ok {
+			err := tx.Exec("UPDATE videos SET expiration_date = ? WHERE id = ?", expirationDate, row.ID).Error
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		logger.Warn(
+			"Failed to parse video expiration date",
+			zap.Uint("videoID", row.ID),
+			zap.String("expirationDate", *row.ExpirationDateText),
+		)
+
+		err := tx.Exec(
+			"INSERT INTO expiration_date_parse_failures (video_id, expiration_date, failed_at) VALUES (?, ?, ?)",
+			row.ID,
+			*row.ExpirationDateText,
+			now,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		failed++
+	}
+
+	logger.Info(
+		"Migrated video expiration dates",
+		zap.Int("parsed", len(rows)-failed),
+		zap.Int("failed", failed),
+		zap.String("report", "expiration_date_parse_failures"),
+	)
+
+	return tx.Exec("ALTER TABLE videos DROP COLUMN expiration_date_text").Error
+}
+
+// parseLegacyDate parse text with the first matching legacy layout, in UTC.
+func parseLegacyDate(text string) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+
+	for _, layout := range legacyDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,305 @@
+// Package migrations versioned database schema migrations.
+//
+// Migrations are SQL scripts embedded in the binary, one directory per database dialect, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql. Applied versions are recorded in the schema_migrations
+// table.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var scripts embed.FS
+
+// advisoryLockKey Postgres advisory lock serializing migrations across replicas.
+const advisoryLockKey = 7_246_014_801
+
+// hooks data migrations by version, run in the same transaction after the version's up script. They are for changes
+// that SQL alone cannot express portably, like parsing free-form text.
+var hooks = map[uint]func(tx *gorm.DB, logger *zap.Logger) error{}
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Migration single versioned schema change.
+type Migration struct {
+	// Down script reverting the change.
+	Down string
+	// Name migration name.
+	Name string
+	// Up script applying the change.
+	Up string
+	// Version migration version, applied in ascending order.
+	Version uint
+
+	hook func(tx *gorm.DB, logger *zap.Logger) error
+}
+
+// Migrator applies and reverts migrations against a database.
+type Migrator struct {
+	db         *gorm.DB
+	logger     *zap.Logger
+	migrations []Migration
+}
+
+// Status migration and when it was applied.
+type Status struct {
+	Migration
+	// AppliedAt when the migration was applied, or nil if it is pending.
+	AppliedAt *time.Time
+}
+
+// appliedMigration schema_migrations row.
+type appliedMigration struct {
+	AppliedAt time.Time
+	Name      string
+	Version   uint
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// New create a Migrator for the migrations matching db's dialect.
+func New(db *gorm.DB, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, logger: logger, migrations: migrations}, nil
+}
+
+// Down revert the latest steps applied migrations, returning the number reverted.
+func (migrator *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+
+	for i := len(migrator.migrations) - 1; i >= 0 && reverted < steps; i-- {
+		migration := migrator.migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		migrator.logger.Info(
+			"Reverting migration",
+			zap.Uint("version", migration.Version),
+			zap.String("name", migration.Name),
+		)
+
+		err = migrator.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migrator.lock(tx); err != nil {
+				return err
+			}
+
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+
+			return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		reverted++
+	}
+
+	return reverted, nil
+}
+
+// Status every known migration and whether it has been applied.
+func (migrator *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrator.migrations))
+
+	for _, migration := range migrator.migrations {
+		status := Status{Migration: migration}
+
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up apply every pending migration, returning the number applied.
+func (migrator *Migrator) Up(ctx context.Context) (int, error) {
+	if _, err := migrator.applied(ctx); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+
+	for _, migration := range migrator.migrations {
+		ran := false
+
+		err := migrator.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migrator.lock(tx); err != nil {
+				return err
+			}
+
+			// Checked under the lock so that replicas starting together apply each migration once.
+			var count int64
+			err := tx.Table("schema_migrations").Where("version = ?", migration.Version).Count(&count).Error
+			if err != nil {
+				return err
+			}
+
+			if count > 0 {
+				return nil
+			}
+
+			migrator.logger.Info(
+				"Applying migration",
+				zap.Uint("version", migration.Version),
+				zap.String("name", migration.Name),
+			)
+
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+
+			if migration.hook != nil {
+				if err := migration.hook(tx, migrator.logger); err != nil {
+					return err
+				}
+			}
+
+			ran = true
+
+			return tx.Exec(
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version,
+				migration.Name,
+				time.Now().UTC(),
+			).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		if ran {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+/* ****************************************************************************************************************** *
+ *                                                 Private functions                                                  *
+ * ****************************************************************************************************************** */
+
+// applied schema_migrations rows by version, creating the table if necessary.
+func (migrator *Migrator) applied(ctx context.Context) (map[uint]appliedMigration, error) {
+	db := migrator.db.WithContext(ctx)
+
+	err := db.Exec(
+		"CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version bigint PRIMARY KEY, " +
+			"name text NOT NULL, " +
+			"applied_at timestamp NOT NULL)",
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []appliedMigration
+	if err := db.Table("schema_migrations").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]appliedMigration, len(rows))
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// load parse the embedded scripts for dialect into migrations ordered by version.
+func load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", dialect, err)
+	}
+
+	byVersion := make(map[uint]*Migration, len(entries)/2)
+
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		versionText, name, hasName := strings.Cut(base, "_")
+		version, parseErr := strconv.ParseUint(versionText, 10, 0)
+
+		if !ok || !hasName || parseErr != nil || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("malformed migration file name %q", entry.Name())
+		}
+
+		script, readErr := fs.ReadFile(scripts, path.Join(dialect, entry.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		migration, exists := byVersion[uint(version)]
+		if !exists {
+			migration = &Migration{Name: name, Version: uint(version), hook: hooks[uint(version)]}
+			byVersion[uint(version)] = migration
+		}
+
+		if direction == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s lacks an up or down script", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// lock serialize migrations across replicas for the rest of tx. SQLite serializes writers on its own.
+func (migrator *Migrator) lock(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockKey).Error
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS videos;
+DROP TABLE IF EXISTS containers;
+
+DROP TYPE IF EXISTS video_type;
+DROP TYPE IF EXISTS asset_type;
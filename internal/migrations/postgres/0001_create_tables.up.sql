@@ -0,0 +1,58 @@
+DO $$
+BEGIN
+    CREATE TYPE asset_type AS ENUM ('ADVERTISEMENT', 'IMAGE');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+DO $$
+BEGIN
+    CREATE TYPE video_type AS ENUM ('CLIP', 'EPISODE', 'MOVIE');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS containers (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    description text,
+    name        text
+);
+
+CREATE INDEX IF NOT EXISTS idx_containers_deleted_at ON containers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS videos (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz,
+    container_id    bigint CONSTRAINT fk_containers_videos REFERENCES containers (id),
+    description     text,
+    expiration_date text,
+    playback_url    text,
+    title           text,
+    video_type      video_type
+);
+
+CREATE INDEX IF NOT EXISTS idx_videos_deleted_at ON videos (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_videos_container_id ON videos (container_id);
+
+CREATE TABLE IF NOT EXISTS assets (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    asset_type   asset_type,
+    container_id bigint CONSTRAINT fk_containers_assets REFERENCES containers (id),
+    name         text,
+    url          text,
+    video_id     bigint
+);
+
+CREATE INDEX IF NOT EXISTS idx_assets_deleted_at ON assets (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_assets_container_id ON assets (container_id);
+CREATE INDEX IF NOT EXISTS idx_assets_video_id ON assets (video_id);
@@ -0,0 +1,16 @@
+ALTER TABLE videos ADD COLUMN expiration_date_text text;
+
+UPDATE videos
+SET expiration_date_text = to_char(expiration_date AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+WHERE expiration_date IS NOT NULL;
+
+UPDATE videos
+SET expiration_date_text = failures.expiration_date
+FROM expiration_date_parse_failures failures
+WHERE failures.video_id = videos.id;
+
+DROP INDEX idx_videos_expiration_date;
+ALTER TABLE videos DROP COLUMN expiration_date;
+ALTER TABLE videos RENAME COLUMN expiration_date_text TO expiration_date;
+
+DROP TABLE expiration_date_parse_failures;
@@ -0,0 +1,4 @@
+DROP INDEX idx_videos_expiry_pending;
+
+ALTER TABLE videos DROP COLUMN expiry_processed_at;
+ALTER TABLE videos DROP COLUMN archived_at;
@@ -0,0 +1,4 @@
+ALTER TABLE videos ADD COLUMN archived_at timestamptz;
+ALTER TABLE videos ADD COLUMN expiry_processed_at timestamptz;
+
+CREATE INDEX idx_videos_expiry_pending ON videos (expiration_date) WHERE expiry_processed_at IS NULL;
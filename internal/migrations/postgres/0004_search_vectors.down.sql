@@ -0,0 +1,5 @@
+DROP INDEX idx_assets_search_vector;
+DROP INDEX idx_videos_search_vector;
+
+ALTER TABLE assets DROP COLUMN search_vector;
+ALTER TABLE videos DROP COLUMN search_vector;
@@ -0,0 +1,11 @@
+ALTER TABLE videos ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+ALTER TABLE assets ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A')
+) STORED;
+
+CREATE INDEX idx_videos_search_vector ON videos USING GIN (search_vector);
+CREATE INDEX idx_assets_search_vector ON assets USING GIN (search_vector);
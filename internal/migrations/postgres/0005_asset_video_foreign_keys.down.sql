@@ -0,0 +1,5 @@
+ALTER TABLE assets DROP CONSTRAINT fk_videos_assets;
+
+DROP INDEX idx_videos_id_container_id;
+
+UPDATE assets SET video_id = 0 WHERE video_id IS NULL;
@@ -0,0 +1,11 @@
+-- Assets that belong to no video store NULL rather than 0, so that video_id can reference videos.
+UPDATE assets SET video_id = NULL WHERE video_id = 0;
+
+CREATE UNIQUE INDEX idx_videos_id_container_id ON videos (id, container_id);
+
+-- Referencing the video together with its container also keeps each asset in its video's container; moving a video
+-- moves its assets along. NOT VALID enforces the constraint on new and changed rows only, so that existing rows that
+-- break it do not fail the migration; the integrityReport query lists them.
+ALTER TABLE assets
+    ADD CONSTRAINT fk_videos_assets FOREIGN KEY (video_id, container_id) REFERENCES videos (id, container_id)
+        ON UPDATE CASCADE NOT VALID;
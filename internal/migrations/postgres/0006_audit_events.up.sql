@@ -0,0 +1,24 @@
+CREATE TABLE audit_events (
+    id              bigserial PRIMARY KEY,
+    actor           text        NOT NULL,
+    after_snapshot  jsonb,
+    before_snapshot jsonb,
+    entity_id       bigint      NOT NULL,
+    entity_type     text        NOT NULL,
+    occurred_at     timestamptz NOT NULL,
+    operation       text        NOT NULL
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX idx_audit_events_occurred_at ON audit_events (occurred_at);
+
+CREATE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
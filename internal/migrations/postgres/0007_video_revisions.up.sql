@@ -0,0 +1,27 @@
+-- Every existing video starts with revision 1, its state as of this migration.
+CREATE TABLE video_revisions (
+    id              bigserial PRIMARY KEY,
+    actor           text        NOT NULL,
+    archived_at     timestamptz,
+    container_id    bigint,
+    created_at      timestamptz NOT NULL,
+    deleted_at      timestamptz,
+    description     text,
+    expiration_date timestamptz,
+    operation       text        NOT NULL,
+    playback_url    text,
+    revision        bigint      NOT NULL,
+    title           text,
+    video_id        bigint      NOT NULL CONSTRAINT fk_videos_video_revisions REFERENCES videos (id) ON DELETE CASCADE,
+    video_type      video_type
+);
+
+CREATE UNIQUE INDEX idx_video_revisions_video_id_revision ON video_revisions (video_id, revision);
+
+INSERT INTO video_revisions (
+    actor, archived_at, container_id, created_at, deleted_at, description, expiration_date, operation, playback_url,
+    revision, title, video_id, video_type
+)
+SELECT 'migration', archived_at, container_id, COALESCE(updated_at, now()), deleted_at, description, expiration_date,
+       'CREATE', playback_url, 1, title, id, video_type
+FROM videos;
@@ -0,0 +1,8 @@
+DROP TRIGGER trg_assets_version ON assets;
+DROP TRIGGER trg_containers_version ON containers;
+DROP TRIGGER trg_videos_version ON videos;
+DROP FUNCTION bump_version();
+
+ALTER TABLE assets DROP COLUMN version;
+ALTER TABLE containers DROP COLUMN version;
+ALTER TABLE videos DROP COLUMN version;
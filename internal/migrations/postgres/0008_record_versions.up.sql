@@ -0,0 +1,15 @@
+ALTER TABLE assets ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE containers ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE videos ADD COLUMN version bigint NOT NULL DEFAULT 1;
+
+-- Every update bumps the version, including those that foreign keys cascade, such as assets moving with their video.
+CREATE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_assets_version BEFORE UPDATE ON assets FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER trg_containers_version BEFORE UPDATE ON containers FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER trg_videos_version BEFORE UPDATE ON videos FOR EACH ROW EXECUTE FUNCTION bump_version();
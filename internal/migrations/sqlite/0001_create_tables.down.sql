@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS videos;
+DROP TABLE IF EXISTS containers;
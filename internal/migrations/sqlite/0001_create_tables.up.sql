@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS containers (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    created_at  datetime,
+    updated_at  datetime,
+    deleted_at  datetime,
+    description text,
+    name        text
+);
+
+CREATE INDEX IF NOT EXISTS idx_containers_deleted_at ON containers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS videos (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    created_at      datetime,
+    updated_at      datetime,
+    deleted_at      datetime,
+    container_id    integer,
+    description     text,
+    expiration_date text,
+    playback_url    text,
+    title           text,
+    video_type      text,
+    CONSTRAINT fk_containers_videos FOREIGN KEY (container_id) REFERENCES containers (id),
+    CONSTRAINT chk_videos_video_type CHECK (video_type IN ('CLIP', 'EPISODE', 'MOVIE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_videos_deleted_at ON videos (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_videos_container_id ON videos (container_id);
+
+CREATE TABLE IF NOT EXISTS assets (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime,
+    asset_type   text,
+    container_id integer,
+    name         text,
+    url          text,
+    video_id     integer,
+    CONSTRAINT fk_containers_assets FOREIGN KEY (container_id) REFERENCES containers (id),
+    CONSTRAINT chk_assets_asset_type CHECK (asset_type IN ('ADVERTISEMENT', 'IMAGE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_assets_deleted_at ON assets (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_assets_container_id ON assets (container_id);
+CREATE INDEX IF NOT EXISTS idx_assets_video_id ON assets (video_id);
@@ -0,0 +1,17 @@
+ALTER TABLE videos ADD COLUMN expiration_date_text text;
+
+UPDATE videos
+SET expiration_date_text = strftime('%Y-%m-%dT%H:%M:%SZ', expiration_date)
+WHERE expiration_date IS NOT NULL;
+
+UPDATE videos
+SET expiration_date_text = (
+    SELECT failures.expiration_date FROM expiration_date_parse_failures failures WHERE failures.video_id = videos.id
+)
+WHERE id IN (SELECT video_id FROM expiration_date_parse_failures);
+
+DROP INDEX idx_videos_expiration_date;
+ALTER TABLE videos DROP COLUMN expiration_date;
+ALTER TABLE videos RENAME COLUMN expiration_date_text TO expiration_date;
+
+DROP TABLE expiration_date_parse_failures;
@@ -0,0 +1,13 @@
+-- Existing text values are parsed into expiration_date by the Go hook in 0002_typed_expiration_dates.go, which then
+-- drops expiration_date_text. Values that cannot be parsed are left NULL and recorded in
+-- expiration_date_parse_failures.
+ALTER TABLE videos RENAME COLUMN expiration_date TO expiration_date_text;
+ALTER TABLE videos ADD COLUMN expiration_date datetime;
+
+CREATE INDEX idx_videos_expiration_date ON videos (expiration_date);
+
+CREATE TABLE expiration_date_parse_failures (
+    video_id        integer PRIMARY KEY,
+    expiration_date text,
+    failed_at       datetime NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE videos ADD COLUMN archived_at datetime;
+ALTER TABLE videos ADD COLUMN expiry_processed_at datetime;
+
+CREATE INDEX idx_videos_expiry_pending ON videos (expiration_date) WHERE expiry_processed_at IS NULL;
@@ -0,0 +1,40 @@
+-- Detached assets get their original video back.
+CREATE TABLE assets_old (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime,
+    asset_type   text,
+    container_id integer,
+    name         text,
+    url          text,
+    video_id     integer,
+    CONSTRAINT fk_containers_assets FOREIGN KEY (container_id) REFERENCES containers (id),
+    CONSTRAINT chk_assets_asset_type CHECK (asset_type IN ('ADVERTISEMENT', 'IMAGE'))
+);
+
+INSERT INTO assets_old (id, created_at, updated_at, deleted_at, asset_type, container_id, name, url, video_id)
+SELECT id,
+       created_at,
+       updated_at,
+       deleted_at,
+       asset_type,
+       container_id,
+       name,
+       url,
+       COALESCE(
+           video_id,
+           (SELECT detached_asset_videos.video_id FROM detached_asset_videos WHERE asset_id = assets.id),
+           0
+       )
+FROM assets;
+
+DROP TABLE assets;
+ALTER TABLE assets_old RENAME TO assets;
+
+CREATE INDEX idx_assets_deleted_at ON assets (deleted_at);
+CREATE INDEX idx_assets_container_id ON assets (container_id);
+CREATE INDEX idx_assets_video_id ON assets (video_id);
+
+DROP TABLE detached_asset_videos;
+DROP INDEX idx_videos_id_container_id;
@@ -0,0 +1,60 @@
+-- SQLite cannot add constraints to an existing table, so assets is rebuilt with them. Every copied row must satisfy
+-- them: assets whose video is missing or belongs to another container are detached from it, and recorded in
+-- detached_asset_videos. Assets that belong to no video store NULL rather than 0, so that video_id can reference
+-- videos.
+CREATE UNIQUE INDEX idx_videos_id_container_id ON videos (id, container_id);
+
+CREATE TABLE detached_asset_videos (
+    asset_id    integer PRIMARY KEY,
+    video_id    integer,
+    detached_at datetime NOT NULL
+);
+
+INSERT INTO detached_asset_videos (asset_id, video_id, detached_at)
+SELECT id, video_id, CURRENT_TIMESTAMP
+FROM assets
+WHERE video_id IS NOT NULL
+  AND video_id <> 0
+  AND NOT EXISTS (
+    SELECT 1 FROM videos WHERE videos.id = assets.video_id AND videos.container_id = assets.container_id
+  );
+
+-- Referencing the video together with its container also keeps each asset in its video's container; moving a video
+-- moves its assets along.
+CREATE TABLE assets_new (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime,
+    asset_type   text,
+    container_id integer,
+    name         text,
+    url          text,
+    video_id     integer,
+    CONSTRAINT fk_containers_assets FOREIGN KEY (container_id) REFERENCES containers (id),
+    CONSTRAINT fk_videos_assets FOREIGN KEY (video_id, container_id) REFERENCES videos (id, container_id)
+        ON UPDATE CASCADE,
+    CONSTRAINT chk_assets_asset_type CHECK (asset_type IN ('ADVERTISEMENT', 'IMAGE'))
+);
+
+INSERT INTO assets_new (id, created_at, updated_at, deleted_at, asset_type, container_id, name, url, video_id)
+SELECT id,
+       created_at,
+       updated_at,
+       deleted_at,
+       asset_type,
+       container_id,
+       name,
+       url,
+       CASE
+           WHEN video_id = 0 OR id IN (SELECT asset_id FROM detached_asset_videos) THEN NULL
+           ELSE video_id
+       END
+FROM assets;
+
+DROP TABLE assets;
+ALTER TABLE assets_new RENAME TO assets;
+
+CREATE INDEX idx_assets_deleted_at ON assets (deleted_at);
+CREATE INDEX idx_assets_container_id ON assets (container_id);
+CREATE INDEX idx_assets_video_id ON assets (video_id);
@@ -0,0 +1,24 @@
+CREATE TABLE audit_events (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    actor           text     NOT NULL,
+    after_snapshot  text,
+    before_snapshot text,
+    entity_id       integer  NOT NULL,
+    entity_type     text     NOT NULL,
+    occurred_at     datetime NOT NULL,
+    operation       text     NOT NULL
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX idx_audit_events_occurred_at ON audit_events (occurred_at);
+
+CREATE TRIGGER trg_audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER trg_audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
@@ -0,0 +1,28 @@
+-- Every existing video starts with revision 1, its state as of this migration.
+CREATE TABLE video_revisions (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    actor           text     NOT NULL,
+    archived_at     datetime,
+    container_id    integer,
+    created_at      datetime NOT NULL,
+    deleted_at      datetime,
+    description     text,
+    expiration_date datetime,
+    operation       text     NOT NULL,
+    playback_url    text,
+    revision        integer  NOT NULL,
+    title           text,
+    video_id        integer  NOT NULL,
+    video_type      text,
+    CONSTRAINT fk_videos_video_revisions FOREIGN KEY (video_id) REFERENCES videos (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_video_revisions_video_id_revision ON video_revisions (video_id, revision);
+
+INSERT INTO video_revisions (
+    actor, archived_at, container_id, created_at, deleted_at, description, expiration_date, operation, playback_url,
+    revision, title, video_id, video_type
+)
+SELECT 'migration', archived_at, container_id, COALESCE(updated_at, CURRENT_TIMESTAMP), deleted_at, description,
+       expiration_date, 'CREATE', playback_url, 1, title, id, video_type
+FROM videos;
@@ -0,0 +1,7 @@
+DROP TRIGGER trg_assets_version;
+DROP TRIGGER trg_containers_version;
+DROP TRIGGER trg_videos_version;
+
+ALTER TABLE assets DROP COLUMN version;
+ALTER TABLE containers DROP COLUMN version;
+ALTER TABLE videos DROP COLUMN version;
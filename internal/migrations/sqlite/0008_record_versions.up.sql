@@ -0,0 +1,20 @@
+ALTER TABLE assets ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE containers ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE videos ADD COLUMN version integer NOT NULL DEFAULT 1;
+
+-- Every update bumps the version, including those that foreign keys cascade, such as assets moving with their video.
+-- SQLite triggers cannot assign NEW, so they update the row again, which the WHEN clause keeps from recursing.
+CREATE TRIGGER trg_assets_version AFTER UPDATE ON assets FOR EACH ROW WHEN NEW.version = OLD.version
+BEGIN
+    UPDATE assets SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER trg_containers_version AFTER UPDATE ON containers FOR EACH ROW WHEN NEW.version = OLD.version
+BEGIN
+    UPDATE containers SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER trg_videos_version AFTER UPDATE ON videos FOR EACH ROW WHEN NEW.version = OLD.version
+BEGIN
+    UPDATE videos SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
@@ -0,0 +1,7 @@
+DROP INDEX idx_assets_external_id;
+DROP INDEX idx_containers_external_id;
+DROP INDEX idx_videos_external_id;
+
+ALTER TABLE assets DROP COLUMN external_id;
+ALTER TABLE containers DROP COLUMN external_id;
+ALTER TABLE videos DROP COLUMN external_id;
@@ -0,0 +1,8 @@
+ALTER TABLE assets ADD COLUMN external_id text;
+ALTER TABLE containers ADD COLUMN external_id text;
+ALTER TABLE videos ADD COLUMN external_id text;
+
+-- Imports upsert by external ID. Deleted rows keep theirs until purged, so that an import cannot shadow them.
+CREATE UNIQUE INDEX idx_assets_external_id ON assets (external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_containers_external_id ON containers (external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_videos_external_id ON videos (external_id) WHERE external_id IS NOT NULL;
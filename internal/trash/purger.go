@@ -0,0 +1,95 @@
+// Package trash permanent deletion of records that have been deleted for longer than a retention period.
+package trash
+
+import (
+	"RocketContainer.go/internal/data"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// actor who scheduled purges make their changes as, in the audit log.
+const actor = "trash-purger"
+
+// interval how often the purger runs.
+const interval = time.Hour
+
+// lockKey lock electing the single replica that purges.
+const lockKey int64 = 7_246_014_803
+
+/* ****************************************************************************************************************** *
+ *                                                  Type definitions                                                  *
+ * ****************************************************************************************************************** */
+
+// Purger periodically purges the records deleted more than a retention period ago.
+type Purger struct {
+	logger    *zap.Logger
+	retention time.Duration
+	store     data.Store
+}
+
+/* ****************************************************************************************************************** *
+ *                                                     Functions                                                      *
+ * ****************************************************************************************************************** */
+
+// NewPurger create a Purger purging the records in store deleted more than retention ago.
+func NewPurger(store data.Store, logger *zap.Logger, retention time.Duration) *Purger {
+	return &Purger{logger: logger, retention: retention, store: store}
+}
+
+// Purge purge the records deleted more than the retention period ago, if this replica can take the purger lock.
+func (purger *Purger) Purge(ctx context.Context) {
+	unlock, acquired, err := purger.store.TryLock(ctx, lockKey)
+	if err != nil {
+		purger.logger.Error("Failed to take purger lock", zap.Error(err))
+
+		return
+	}
+
+	if !acquired {
+		purger.logger.Debug("Another replica holds the purger lock")
+
+		return
+	}
+
+	defer unlock()
+
+	deletedBefore := time.Now().Add(-purger.retention)
+
+	result, err := purger.store.Purge(ctx, deletedBefore)
+	if err != nil {
+		purger.logger.Error("Failed to purge deleted records", zap.Error(err))
+
+		return
+	}
+
+	purger.logger.Info(
+		"Purged deleted records",
+		zap.Time("deletedBefore", deletedBefore),
+		zap.Int64("assets", result.Assets),
+		zap.Int64("containers", result.Containers),
+		zap.Int64("videos", result.Videos),
+	)
+}
+
+// Run purge immediately and then every hour until ctx is cancelled.
+func (purger *Purger) Run(ctx context.Context) {
+	ctx = data.WithActor(ctx, actor)
+
+	purger.logger.Info("Starting purger", zap.Duration("retention", purger.retention))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purger.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			purger.logger.Info("Stopping purger")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}